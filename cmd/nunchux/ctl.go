@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"nunchux/internal/config"
+	"nunchux/internal/daemon"
+	"nunchux/internal/launch"
+)
+
+const ctlUsage = `usage: nunchux ctl <command> [args]
+
+commands:
+  list [--json]                   List items with status and running state
+  launch <name> [--action A]      Launch an app or task
+  kill <name>                     Kill an app or task window
+  run <runner:task> [--action A] [args...]
+                                  Run a taskrunner task with parameter values
+  events                          Stream launch events as JSON lines
+
+launch, kill and run accept --dir to override the working directory.
+Arguments after -- are passed to the task as-is.
+`
+
+// runCtl handles `nunchux ctl`, a client for the daemon's control API
+func runCtl(args []string) {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, ctlUsage)
+		os.Exit(2)
+	}
+
+	cmd := args[0]
+	fs := flag.NewFlagSet("ctl "+cmd, flag.ExitOnError)
+	jsonFlag := fs.Bool("json", false, "Output JSON")
+	actionFlag := fs.String("action", "", "Launch action (popup, window, background_window, pane_*)")
+	dirFlag := fs.String("dir", "", "Working directory (default: current directory)")
+	positional := parseInterspersed(fs, args[1:])
+
+	if cmd == "events" {
+		err := daemon.Subscribe(func(e launch.Event) {
+			json.NewEncoder(os.Stdout).Encode(e)
+		})
+		ctlFail(err)
+		return
+	}
+
+	ctx, err := ctlContext(*dirFlag)
+	ctlFail(err)
+
+	switch cmd {
+	case "list":
+		var list []daemon.ItemInfo
+		ctlFail(daemon.Call(daemon.MethodItemsList, ctx, &list))
+		if *jsonFlag {
+			json.NewEncoder(os.Stdout).Encode(list)
+			return
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		for _, item := range list {
+			running := ""
+			if item.Running {
+				running = "running"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", item.Name, item.Type, running, item.Status)
+		}
+		w.Flush()
+
+	case "launch", "kill", "run":
+		if len(positional) < 1 || (len(positional) > 1 && cmd != "run") {
+			fmt.Fprint(os.Stderr, ctlUsage)
+			os.Exit(2)
+		}
+		params := daemon.ItemParams{Context: ctx, Name: positional[0], Action: config.Action(*actionFlag), Args: positional[1:]}
+		method := map[string]string{
+			"launch": daemon.MethodItemsLaunch,
+			"kill":   daemon.MethodItemsKill,
+			"run":    daemon.MethodTasksRun,
+		}[cmd]
+		ctlFail(daemon.Call(method, params, nil))
+
+	default:
+		fmt.Fprint(os.Stderr, ctlUsage)
+		os.Exit(2)
+	}
+}
+
+// parseInterspersed parses flags placed anywhere among the positional
+// arguments, and returns the positional ones. Everything after "--" is
+// positional.
+func parseInterspersed(fs *flag.FlagSet, args []string) []string {
+	var rest []string
+	for i, arg := range args {
+		if arg == "--" {
+			args, rest = args[:i], args[i+1:]
+			break
+		}
+	}
+
+	var positional []string
+	for {
+		fs.Parse(args)
+		args = fs.Args()
+		if len(args) == 0 {
+			return append(positional, rest...)
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// ctlContext builds the request context from the current config, directory
+// and tmux pane
+func ctlContext(dir string) (daemon.Context, error) {
+	cfgPath, err := config.FindConfigFile()
+	if err != nil {
+		return daemon.Context{}, err
+	}
+	if cfgPath == "" {
+		return daemon.Context{}, fmt.Errorf("no config file found")
+	}
+	cfgPath, _ = filepath.Abs(cfgPath)
+
+	if dir == "" {
+		dir, _ = os.Getwd()
+	}
+	dir, _ = filepath.Abs(dir)
+
+	return daemon.Context{Config: cfgPath, Dir: dir, Pane: os.Getenv("TMUX_PANE")}, nil
+}
+
+func ctlFail(err error) {
+	if err == nil {
+		return
+	}
+	if !daemon.IsRunning() {
+		err = fmt.Errorf("daemon not running (start it with `nunchux daemon`)")
+	}
+	fmt.Fprintln(os.Stderr, "nunchux ctl:", err)
+	os.Exit(1)
+}
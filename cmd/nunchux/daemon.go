@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"nunchux/internal/daemon"
+)
+
+// runDaemon handles `nunchux daemon [start|stop|status]`
+func runDaemon(args []string) {
+	cmd := "start"
+	if len(args) > 0 {
+		cmd = args[0]
+	}
+
+	switch cmd {
+	case "start":
+		server := daemon.NewServer(getBinDir())
+		if err := server.Listen(); err != nil {
+			logError("Daemon: %v", err)
+			fmt.Fprintln(os.Stderr, "nunchux daemon:", err)
+			os.Exit(1)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		logInfo("Daemon listening on %s", daemon.SocketPath())
+		if err := server.Serve(ctx); err != nil {
+			logError("Daemon: %v", err)
+			os.Exit(1)
+		}
+		logInfo("Daemon stopped")
+
+	case "stop":
+		if err := daemon.Call(daemon.MethodShutdown, nil, nil); err != nil {
+			fmt.Fprintln(os.Stderr, "nunchux daemon: not running")
+			os.Exit(1)
+		}
+
+	case "status":
+		if daemon.IsRunning() {
+			fmt.Println("running on", daemon.SocketPath())
+			return
+		}
+		fmt.Println("not running")
+		os.Exit(1)
+
+	default:
+		fmt.Fprintf(os.Stderr, "usage: nunchux daemon [start|stop|status]\n")
+		os.Exit(2)
+	}
+}
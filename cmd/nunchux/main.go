@@ -12,9 +12,12 @@ import (
 	"strings"
 
 	"nunchux/internal/config"
+	"nunchux/internal/daemon"
 	"nunchux/internal/fzf"
 	"nunchux/internal/items"
+	"nunchux/internal/launch"
 	"nunchux/internal/onboarding"
+	"nunchux/internal/shell"
 	"nunchux/internal/tmux"
 	"nunchux/internal/ui"
 )
@@ -30,15 +33,12 @@ func setupLogging(debugMode bool) {
 	debug = debugMode
 
 	// Create log directory
-	cacheDir, err := os.UserCacheDir()
-	if err != nil {
-		cacheDir = filepath.Join(os.Getenv("HOME"), ".cache")
-	}
-	logDir := filepath.Join(cacheDir, "nunchux")
+	logDir := config.CacheDir()
 	os.MkdirAll(logDir, 0755)
 
 	// Open log file (append mode)
 	logPath := filepath.Join(logDir, "nunchux.log")
+	var err error
 	logFile, err = os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
 		// Fall back to stderr if we can't open log file
@@ -91,12 +91,7 @@ func main() {
 	flag.Parse()
 
 	if *logFlag {
-		cacheDir, _ := os.UserCacheDir()
-		if cacheDir == "" {
-			cacheDir = filepath.Join(os.Getenv("HOME"), ".cache")
-		}
-		logPath := filepath.Join(cacheDir, "nunchux", "nunchux.log")
-		fmt.Println(logPath)
+		fmt.Println(filepath.Join(config.CacheDir(), "nunchux.log"))
 		return
 	}
 
@@ -117,6 +112,21 @@ func main() {
 	}
 	logInfo("nunchux started")
 
+	// Subcommands
+	if flag.NArg() > 0 {
+		switch flag.Arg(0) {
+		case "daemon":
+			runDaemon(flag.Args()[1:])
+			return
+		case "ctl":
+			runCtl(flag.Args()[1:])
+			return
+		case "task":
+			runTask(flag.Args()[1:])
+			return
+		}
+	}
+
 	if *debugFlag && flag.NArg() == 0 {
 		cfgPath, _ := config.FindConfigFile()
 		binDir := getBinDir()
@@ -176,6 +186,7 @@ func main() {
 
 	// Create registry and tmux client
 	registry := items.NewRegistry(cfg)
+	defer registry.Close()
 	tmuxClient := tmux.NewClient(binDir)
 
 	// Report shortcut validation errors and exit
@@ -188,7 +199,7 @@ func main() {
 		if ui.ShowConfigErrors(registry.Settings, cfgPath, errorMsgs) {
 			// Launch editor in popup with border, like normal apps
 			editor := ui.GetEditorCommand()
-			cmd := fmt.Sprintf("%s %q", editor, cfgPath)
+			cmd := editor + " " + shell.Quote(cfgPath)
 			tmuxClient.Launch(tmux.LaunchOptions{
 				Action:    config.ActionPopup,
 				Name:      "config",
@@ -214,26 +225,25 @@ func main() {
 		return
 	}
 
-	// Load taskrunners
-	ctx := context.Background()
-	registry.LoadTaskrunners(ctx)
-
 	// Handle menu output (for fzf reload)
+	// Taskrunners are loaded on demand, unless the daemon renders the menu
 	if *menuFlag {
-		runningWindows := tmuxClient.RunningWindows()
-		content := registry.BuildMenu(ctx, runningWindows, *submenuFlag)
+		content := ui.MenuContent(context.Background(), registry, tmuxClient, *submenuFlag)
 		fmt.Print(content)
 		return
 	}
 
+	launcher := launch.New(registry, tmuxClient)
+	launcher.Publish = daemon.Publisher()
+
 	// Handle direct launch by shortcut
 	if *launchShortcutFlag != "" {
-		launchItemByName(registry, tmuxClient, *launchShortcutFlag)
+		launchItemByName(launcher, *launchShortcutFlag)
 		return
 	}
 
 	// Run main menu loop
-	runMenu(registry, tmuxClient, *submenuFlag)
+	runMenu(launcher, *submenuFlag)
 }
 
 func preflight() error {
@@ -287,13 +297,24 @@ func fileExists(path string) bool {
 	return err == nil
 }
 
-func launchItemByName(registry *items.Registry, tmuxClient *tmux.Client, name string) {
+func launchItemByName(launcher *launch.Launcher, name string) {
+	registry := launcher.Registry
+
 	// Handle dirbrowser: prefix
 	lookupName := name
 	if strings.HasPrefix(name, "dirbrowser:") {
 		lookupName = strings.TrimPrefix(name, "dirbrowser:")
 	}
 
+	// Tasks with a shortcut (format: runner:task)
+	if strings.Contains(name, ":") && !strings.HasPrefix(name, "dirbrowser:") {
+		registry.EnsureTaskrunners(context.Background())
+		if tr := registry.FindTaskrunnerItem(name); tr != nil {
+			launchTaskrunner(launcher, tr, "", "")
+			return
+		}
+	}
+
 	item := registry.FindItem(lookupName)
 	if item == nil {
 		logError("Item not found: %s", name)
@@ -302,39 +323,20 @@ func launchItemByName(registry *items.Registry, tmuxClient *tmux.Client, name st
 
 	switch item.Type() {
 	case items.TypeApp:
+		// Launch with primary action (selects the window if already running)
 		app := item.(*items.AppItem)
-
-		// If already running, just select the window
-		if tmuxClient.IsWindowRunning(name) {
-			tmuxClient.SelectWindow(name)
-			return
-		}
-
-		// Launch with primary action
-		action := app.GetPrimaryAction()
-		logInfo("Launching %s (%s) via shortcut", name, action)
-		err := tmuxClient.Launch(tmux.LaunchOptions{
-			Action:    action,
-			Name:      name,
-			Cmd:       app.App.Cmd,
-			Width:     app.GetWidth(),
-			Height:    app.GetHeight(),
-			MaxWidth:  registry.Settings.MaxPopupWidth,
-			MaxHeight: registry.Settings.MaxPopupHeight,
-			OnExit:    app.App.OnExit,
-			IsApp:     true,
-		})
-		if err != nil {
+		logInfo("Launching %s (%s) via shortcut", name, app.GetPrimaryAction())
+		if err := launcher.App(app, "", ""); err != nil {
 			logError("Launch failed for %s: %v", name, err)
 		}
 
 	case items.TypeMenu:
 		// Open the submenu
-		runMenu(registry, tmuxClient, name)
+		runMenu(launcher, name)
 
 	case items.TypeDirbrowser:
 		db := item.(*items.DirbrowserItem)
-		launchDirbrowser(registry, tmuxClient, db)
+		launchDirbrowser(launcher, db)
 	}
 }
 
@@ -399,7 +401,8 @@ func listApps(registry *items.Registry) {
 	}
 }
 
-func launchDirbrowser(registry *items.Registry, tmuxClient *tmux.Client, db *items.DirbrowserItem) {
+func launchDirbrowser(launcher *launch.Launcher, db *items.DirbrowserItem) {
+	registry := launcher.Registry
 	ctx := context.Background()
 
 	for {
@@ -418,15 +421,6 @@ func launchDirbrowser(registry *items.Registry, tmuxClient *tmux.Client, db *ite
 			return
 		}
 
-		// Get editor from environment
-		editor := os.Getenv("VISUAL")
-		if editor == "" {
-			editor = os.Getenv("EDITOR")
-		}
-		if editor == "" {
-			editor = "nvim"
-		}
-
 		// Handle action menu key
 		action := sel.Action
 		if sel.Key == registry.Settings.ActionMenuKey {
@@ -442,25 +436,8 @@ func launchDirbrowser(registry *items.Registry, tmuxClient *tmux.Client, db *ite
 			action = registry.Settings.PrimaryAction
 		}
 
-		// Build editor command
-		cmd := fmt.Sprintf("%s %q", editor, sel.FilePath)
-		windowName := filepath.Base(sel.FilePath)
-		if action == config.ActionPopup {
-			windowName = db.Dirbrowser.Name + " | " + filepath.Base(sel.FilePath)
-		}
-
-		logInfo("Opening %s with %s (%s)", sel.FilePath, editor, action)
-		err = tmuxClient.Launch(tmux.LaunchOptions{
-			Action:    action,
-			Name:      windowName,
-			Cmd:       cmd,
-			Width:     db.GetWidth(),
-			Height:    db.GetHeight(),
-			MaxWidth:  registry.Settings.MaxPopupWidth,
-			MaxHeight: registry.Settings.MaxPopupHeight,
-			IsApp:     false,
-		})
-		if err != nil {
+		logInfo("Opening %s (%s)", sel.FilePath, action)
+		if err := launcher.File(db, sel.FilePath, action); err != nil {
 			logError("Launch failed: %v", err)
 			ui.ShowError(err)
 		}
@@ -468,75 +445,73 @@ func launchDirbrowser(registry *items.Registry, tmuxClient *tmux.Client, db *ite
 	}
 }
 
-func launchTaskrunner(registry *items.Registry, tmuxClient *tmux.Client, tr *items.TaskrunnerItem, key string, action config.Action) {
+func launchTaskrunner(launcher *launch.Launcher, tr *items.TaskrunnerItem, key string, action config.Action) {
+	registry := launcher.Registry
 	windowName := tr.WindowName()
 
-	// Check if already running - reuse window if so
-	isRunning := tmuxClient.IsWindowRunning(windowName)
-
 	// Handle action menu key
 	if key == registry.Settings.ActionMenuKey {
 		var err error
-		action, err = ui.ShowActionMenu(registry.Settings, windowName)
+		action, err = ui.ShowTaskActionMenu(registry.Settings, windowName)
 		if err != nil || action == "" {
 			return // User canceled
 		}
 	}
 
-	// Resolve action
-	if action == "" {
-		action = tr.GetPrimaryAction()
+	// Prompt for parameters, starting from the values used last time
+	var args []string
+	if len(tr.Task.Params) > 0 {
+		dir := registry.WorkDir()
+		var ok bool
+		var err error
+		args, ok, err = ui.PromptTaskParams(registry.Settings, tr, items.LoadTaskArgs(dir, tr.Name()))
+		if err != nil {
+			logError("Parameter prompt failed for %s: %v", tr.Name(), err)
+			ui.ShowError(err)
+			return
+		}
+		if !ok {
+			return // User canceled
+		}
+		if err := items.SaveTaskArgs(dir, tr.Name(), args); err != nil {
+			logError("Saving arguments for %s: %v", tr.Name(), err)
+		}
 	}
 
-	logInfo("Launching taskrunner %s (%s), running=%v", tr.Name(), action, isRunning)
-
-	// Build the command with completion handling
-	cmd := tr.Task.Cmd
-	fullCmd := buildTaskrunnerCmd(registry.Settings, cmd, windowName)
+	if tr.Confirm() {
+		ok, err := ui.ConfirmTask(registry.Settings, tr, args)
+		if err != nil || !ok {
+			return // User declined
+		}
+	}
 
-	err := tmuxClient.Launch(tmux.LaunchOptions{
-		Action:       action,
-		Name:         windowName,
-		Cmd:          fullCmd,
-		Width:        registry.Settings.PopupWidth,
-		Height:       registry.Settings.PopupHeight,
-		MaxWidth:     registry.Settings.MaxPopupWidth,
-		MaxHeight:    registry.Settings.MaxPopupHeight,
-		IsApp:        false,
-		IsTaskrunner: true,
-		ReuseWindow:  isRunning,
-		SuccessIcon:  registry.Settings.TaskrunnerIconSuccess,
-		FailedIcon:   registry.Settings.TaskrunnerIconFailed,
-		RunningIcon:  registry.Settings.TaskrunnerIconRunning,
-	})
-	if err != nil {
+	logInfo("Launching taskrunner %s (%s)", tr.Name(), action)
+	if err := launcher.Task(tr, action, "", args); err != nil {
 		logError("Launch failed for taskrunner %s: %v", tr.Name(), err)
 		ui.ShowError(err)
 	}
 }
 
-// buildTaskrunnerCmd wraps a task command with status indicator and wait
-func buildTaskrunnerCmd(settings *config.Settings, cmd, windowName string) string {
-	// Shell script that runs the command and shows success/failure
-	return fmt.Sprintf(`bash -c '
-source "%s/nunchux-run" 2>/dev/null || true
-%s
-exit_code=$?
-echo
-if [[ $exit_code -eq 0 ]]; then
-    tmux rename-window -t "$TMUX_PANE" "%s %s" 2>/dev/null
-    echo -e "\033[32m✓ Task completed successfully\033[0m"
-else
-    tmux rename-window -t "$TMUX_PANE" "%s %s" 2>/dev/null
-    echo -e "\033[31m✗ Task failed with exit code $exit_code\033[0m"
-fi
-echo
-echo "Press any key to close..."
-read -n 1 -s
-'`, settings.BinDir, cmd, windowName, settings.TaskrunnerIconSuccess, windowName, settings.TaskrunnerIconFailed)
+func launchPipeline(launcher *launch.Launcher, p *items.PipelineItem, key string, action config.Action) {
+	registry := launcher.Registry
+	if key == registry.Settings.ActionMenuKey {
+		var err error
+		action, err = ui.ShowActionMenu(registry.Settings, p.WindowName())
+		if err != nil || action == "" {
+			return // User canceled
+		}
+	}
+
+	logInfo("Launching pipeline %s (%s)", p.Name(), action)
+	if err := launcher.Pipeline(p, action, ""); err != nil {
+		logError("Launch failed for pipeline %s: %v", p.Name(), err)
+		ui.ShowError(err)
+	}
 }
 
-func runMenu(registry *items.Registry, tmuxClient *tmux.Client, currentMenu string) {
+func runMenu(launcher *launch.Launcher, currentMenu string) {
+	registry := launcher.Registry
+	tmuxClient := launcher.Tmux
 	ctx := context.Background()
 	logDebug("Starting menu loop, items: %d", len(registry.Items))
 
@@ -559,6 +534,17 @@ func runMenu(registry *items.Registry, tmuxClient *tmux.Client, currentMenu stri
 			}
 			return
 		}
+		if sel.History {
+			showTaskHistory(launcher)
+			return
+		}
+		if sel.Rerun {
+			if err := rerunLastTask(launcher); err != nil {
+				logError("Rerun failed: %v", err)
+				ui.ShowError(err)
+			}
+			return
+		}
 
 		// Skip divider lines (empty name field)
 		if sel.Name == "" {
@@ -577,11 +563,21 @@ func runMenu(registry *items.Registry, tmuxClient *tmux.Client, currentMenu stri
 
 		// Check for taskrunner items first (format: runner:task)
 		if strings.Contains(sel.Name, ":") && !strings.HasPrefix(sel.Name, "dirbrowser:") {
+			registry.EnsureTaskrunners(ctx)
 			trItem := registry.FindTaskrunnerItem(sel.Name)
 			if trItem != nil {
-				launchTaskrunner(registry, tmuxClient, trItem, sel.Key, sel.Action)
+				launchTaskrunner(launcher, trItem, sel.Key, sel.Action)
 				return
 			}
+			if pipeline := registry.FindPipeline(sel.Name); pipeline != nil {
+				launchPipeline(launcher, pipeline, sel.Key, sel.Action)
+				return
+			}
+			// A runner's task submenu (format: tasks:runner)
+			if strings.HasPrefix(sel.Name, items.TaskMenuPrefix) {
+				currentMenu = sel.Name
+				continue
+			}
 		}
 
 		// Look up item from registry to determine type
@@ -627,18 +623,7 @@ func runMenu(registry *items.Registry, tmuxClient *tmux.Client, currentMenu stri
 
 			// Launch the app
 			logInfo("Launching %s (%s)", sel.Name, action)
-			err := tmuxClient.Launch(tmux.LaunchOptions{
-				Action:    action,
-				Name:      sel.Name,
-				Cmd:       app.App.Cmd,
-				Width:     app.GetWidth(),
-				Height:    app.GetHeight(),
-				MaxWidth:  registry.Settings.MaxPopupWidth,
-				MaxHeight: registry.Settings.MaxPopupHeight,
-				OnExit:    app.App.OnExit,
-				IsApp:     true,
-			})
-			if err != nil {
+			if err := launcher.App(app, action, ""); err != nil {
 				logError("Launch failed for %s: %v", sel.Name, err)
 				ui.ShowError(err)
 			}
@@ -646,7 +631,7 @@ func runMenu(registry *items.Registry, tmuxClient *tmux.Client, currentMenu stri
 
 		case items.TypeDirbrowser:
 			db := item.(*items.DirbrowserItem)
-			launchDirbrowser(registry, tmuxClient, db)
+			launchDirbrowser(launcher, db)
 			return
 		}
 	}
@@ -671,7 +656,7 @@ func handleEditConfig(registry *items.Registry, tmuxClient *tmux.Client) {
 	}
 
 	editor := ui.GetEditorCommand()
-	cmd := fmt.Sprintf("%s %q", editor, cfgPath)
+	cmd := editor + " " + shell.Quote(cfgPath)
 
 	tmuxClient.Launch(tmux.LaunchOptions{
 		Action:    config.ActionPopup,
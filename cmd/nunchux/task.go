@@ -0,0 +1,317 @@
+package main
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"nunchux/internal/config"
+	"nunchux/internal/daemon"
+	"nunchux/internal/history"
+	"nunchux/internal/ignore"
+	"nunchux/internal/items"
+	"nunchux/internal/launch"
+	"nunchux/internal/shell"
+	"nunchux/internal/tmux"
+	"nunchux/internal/ui"
+	"nunchux/internal/watch"
+)
+
+const taskUsage = `usage: nunchux task <command> [args]
+
+commands:
+  history                         List recorded task runs, newest first
+  rerun                           Run the task launched last in this project again
+  record [--notify MODE] <exit_code> <run>
+                                  Record a finished run (used by the task wrapper)
+  watch --window ID --dir DIR <runner:task> [-- args...]
+                                  Re-run a task in a window when files change
+                                  (started by the watch action)
+`
+
+// runTask handles `nunchux task`
+func runTask(args []string) {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, taskUsage)
+		os.Exit(2)
+	}
+
+	switch args[0] {
+	case "history":
+		runs, err := history.Load()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "nunchux task:", err)
+			os.Exit(1)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		for _, run := range runs {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\n", run.Start.Format("2006-01-02 15:04:05"),
+				run.Name, run.ExitCode, history.FormatDuration(run.Duration), run.Dir, run.Log)
+		}
+		w.Flush()
+
+	case "rerun":
+		launcher, err := newTaskLauncher("")
+		if err == nil {
+			defer launcher.Registry.Close()
+			err = rerunLastTask(launcher)
+		}
+		if err != nil {
+			logError("Rerun failed: %v", err)
+			fmt.Fprintln(os.Stderr, "nunchux task rerun:", err)
+			os.Exit(1)
+		}
+
+	case "record":
+		fs := flag.NewFlagSet("task record", flag.ExitOnError)
+		notifyFlag := fs.String("notify", "", "Completion notification (tmux, bell, desktop or none)")
+		fs.Parse(args[1:])
+		if fs.NArg() != 2 {
+			fmt.Fprint(os.Stderr, taskUsage)
+			os.Exit(2)
+		}
+		run, err := recordRun(fs.Arg(0), fs.Arg(1))
+		if err != nil {
+			logError("Recording task run: %v", err)
+			os.Exit(1)
+		}
+		if err := tmux.Notify(*notifyFlag, run.Client, "nunchux", runSummary(run)); err != nil {
+			logError("Notifying about %s: %v", run.Name, err)
+		}
+
+	case "watch":
+		fs := flag.NewFlagSet("task watch", flag.ExitOnError)
+		windowFlag := fs.String("window", "", "ID of the task's window")
+		dirFlag := fs.String("dir", "", "Directory the task runs in")
+		positional := parseInterspersed(fs, args[1:])
+		if *windowFlag == "" || *dirFlag == "" || len(positional) < 1 {
+			fmt.Fprint(os.Stderr, taskUsage)
+			os.Exit(2)
+		}
+		if err := watchTask(*windowFlag, *dirFlag, positional[0], positional[1:]); err != nil {
+			logError("Watching %s: %v", positional[0], err)
+			os.Exit(1)
+		}
+
+	default:
+		fmt.Fprint(os.Stderr, taskUsage)
+		os.Exit(2)
+	}
+}
+
+// watchDebounce is how long the tree must be quiet before a re-run
+const watchDebounce = 300 * time.Millisecond
+
+// watchTask re-runs a task in its window whenever files in its project
+// change, until the window is closed
+func watchTask(windowID, dir, name string, args []string) error {
+	// One watcher per window
+	lock, err := lockWatch(windowID)
+	if err != nil {
+		return nil // Already watched
+	}
+	defer lock.Close()
+
+	launcher, err := newTaskLauncher(dir)
+	if err != nil {
+		return err
+	}
+	registry, tmuxClient := launcher.Registry, launcher.Tmux
+	defer registry.Close()
+	registry.EnsureTaskrunners(context.Background())
+	tr := registry.FindTaskrunnerItem(name)
+	if tr == nil {
+		return fmt.Errorf("task not found: %s", name)
+	}
+
+	root := history.ProjectRoot(dir)
+	watcher, err := watch.New(root, watchSkip(root, registry.Settings.ExcludePatterns), watchDebounce)
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+	logInfo("Watching %s for %s in window %s", root, name, windowID)
+
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-watcher.Changes():
+			if !tmuxClient.WindowExists(windowID) {
+				return nil
+			}
+			// Pick up changes to the task's definition
+			registry.LoadTaskrunners(context.Background())
+			if current := registry.FindTaskrunnerItem(name); current != nil {
+				tr = current
+			}
+			logDebug("Change in %s, re-running %s", root, name)
+			if err := launcher.RespawnWatched(tr, windowID, dir, args); err != nil {
+				return err
+			}
+		case <-ticker.C:
+			if !tmuxClient.WindowExists(windowID) {
+				logInfo("Window %s closed, no longer watching %s", windowID, name)
+				return nil
+			}
+		}
+	}
+}
+
+// newTaskLauncher loads the config and returns a launcher for tasks in dir
+// (empty = the tmux pane's directory), for task subcommands run outside the
+// menu
+func newTaskLauncher(dir string) (*launch.Launcher, error) {
+	cfgPath, err := config.FindConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		return nil, err
+	}
+	binDir := getBinDir()
+	cfg.Settings.BinDir = binDir
+
+	registry := items.NewRegistry(cfg)
+	registry.Dir = dir
+	launcher := launch.New(registry, tmux.NewClient(binDir))
+	launcher.Publish = daemon.Publisher()
+	return launcher, nil
+}
+
+// rerunLastTask runs the task launched last in the current project again,
+// with the same action and arguments, as launching it from the menu does:
+// with its current command, hooks and confirm setting. A watched task goes
+// back to its watch window. A task that no longer exists runs the command
+// it ran last.
+func rerunLastTask(launcher *launch.Launcher) error {
+	registry := launcher.Registry
+	last, ok := history.LastLaunch(registry.WorkDir())
+	if !ok {
+		return fmt.Errorf("no task launched in %s yet", history.ProjectRoot(registry.WorkDir()))
+	}
+
+	registry.EnsureTaskrunners(context.Background())
+	tr := registry.FindTaskrunnerItem(last.Name)
+	if tr == nil {
+		logInfo("Rerunning %s (%s) from its last command", last.Name, last.Action)
+		return launcher.Rerun(last, "")
+	}
+	if tr.Confirm() {
+		ok, err := ui.ConfirmTask(registry.Settings, tr, last.Args)
+		if err != nil || !ok {
+			return err // Declined
+		}
+	}
+	logInfo("Rerunning %s (%s)", last.Name, last.Action)
+	return launcher.Task(tr, last.Action, last.Dir, last.Args)
+}
+
+// watchSkip skips .git, exclude_patterns and what the project's ignore
+// files ignore, nested ones and git's global excludes included
+func watchSkip(root, excludePatterns string) watch.SkipFunc {
+	excludes := ignore.New(ignore.SplitList(excludePatterns))
+	ignored := ignore.LoadTree(root)
+	return func(rel string, isDir bool) bool {
+		return rel == ".git" || excludes.Match(rel, isDir) || ignored.Match(rel, isDir)
+	}
+}
+
+// lockWatch takes the watch lock of a window in the current tmux server,
+// failing if another watcher holds it
+func lockWatch(windowID string) (*os.File, error) {
+	dir := filepath.Join(config.CacheDir(), "watch")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	sum := sha1.Sum([]byte(os.Getenv("TMUX") + windowID))
+	f, err := os.OpenFile(filepath.Join(dir, hex.EncodeToString(sum[:8])+".lock"), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// recordRun completes a run started by the task wrapper and records it
+func recordRun(exitCode, encoded string) (history.Run, error) {
+	run, err := history.Decode(encoded)
+	if err != nil {
+		return run, err
+	}
+	run.ExitCode, err = strconv.Atoi(exitCode)
+	if err != nil {
+		return run, err
+	}
+	run.Duration = time.Since(run.Start).Round(time.Millisecond)
+	if run.Dir == "" {
+		// The wrapper runs in the task's directory
+		run.Dir, _ = os.Getwd()
+	}
+	return run, history.Record(run)
+}
+
+// runSummary describes how a run finished, for notifications
+func runSummary(run history.Run) string {
+	if run.Succeeded() {
+		return fmt.Sprintf("%s succeeded in %s", run.Name, history.FormatDuration(run.Duration))
+	}
+	return fmt.Sprintf("%s failed with exit code %d after %s", run.Name, run.ExitCode, history.FormatDuration(run.Duration))
+}
+
+// showTaskHistory shows the task history view and opens the selected run's
+// log in a popup, or re-runs its command
+func showTaskHistory(launcher *launch.Launcher) {
+	settings := launcher.Registry.Settings
+	runs, err := history.Load()
+	if err != nil {
+		logError("Loading task history: %v", err)
+		ui.ShowError(err)
+		return
+	}
+
+	sel, err := ui.ShowTaskHistory(settings, runs)
+	if err != nil {
+		ui.ShowError(err)
+		return
+	}
+	if sel == nil {
+		return // User canceled
+	}
+
+	if sel.Rerun {
+		logInfo("Re-running %s in %s", sel.Run.Name, sel.Run.Dir)
+		if err := launcher.Rerun(sel.Run, ""); err != nil {
+			logError("Re-run failed for %s: %v", sel.Run.Name, err)
+			ui.ShowError(err)
+		}
+		return
+	}
+
+	err = launcher.Tmux.Launch(tmux.LaunchOptions{
+		Action:    config.ActionPopup,
+		Name:      sel.Run.Name + " log",
+		Cmd:       "less -R +G " + shell.Quote(sel.Run.Log),
+		Dir:       sel.Run.Dir,
+		Width:     settings.PopupWidth,
+		Height:    settings.PopupHeight,
+		MaxWidth:  settings.MaxPopupWidth,
+		MaxHeight: settings.MaxPopupHeight,
+	})
+	if err != nil {
+		logError("Opening log %s: %v", sel.Run.Log, err)
+		ui.ShowError(err)
+	}
+}
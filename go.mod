@@ -3,6 +3,6 @@ module nunchux
 go 1.25.5
 
 require (
-	golang.org/x/sys v0.40.0 // indirect
-	golang.org/x/term v0.39.0 // indirect
+	golang.org/x/sys v0.40.0
+	golang.org/x/term v0.39.0
 )
@@ -23,6 +23,7 @@ func Load(path string) (*Config, error) {
 	defer file.Close()
 
 	cfg := &Config{
+		Path:     path,
 		Settings: DefaultSettings(),
 		Order: OrderConfig{
 			Submenus: make(map[string][]string),
@@ -144,6 +145,12 @@ func flushSection(cfg *Config, sectionType, name string, data map[string]string)
 		if name != "" { // Skip global [taskrunner] section
 			cfg.Taskrunners = append(cfg.Taskrunners, parseTaskrunner(name, data))
 		}
+	case "pipeline":
+		cfg.Pipelines = append(cfg.Pipelines, parsePipeline(name, data))
+	case "task":
+		if runner, task, ok := strings.Cut(name, ":"); ok {
+			cfg.Tasks = append(cfg.Tasks, parseTaskOverride(runner, task, data))
+		}
 	}
 }
 
@@ -152,6 +159,9 @@ func parseApp(name string, data map[string]string) App {
 		Name: name,
 	}
 	for key, value := range data {
+		if parseHook(&app.Hooks, key, value) {
+			continue
+		}
 		switch key {
 		case "cmd":
 			app.Cmd = value
@@ -165,8 +175,6 @@ func parseApp(name string, data map[string]string) App {
 			app.Status = value
 		case "status_script":
 			app.StatusScript = value
-		case "on_exit":
-			app.OnExit = value
 		case "shortcut":
 			app.Shortcut = value
 		case "primary_action":
@@ -202,6 +210,9 @@ func parseDirbrowser(name string, data map[string]string) Dirbrowser {
 	db.Name = name
 
 	for key, value := range data {
+		if parseHook(&db.Hooks, key, value) {
+			continue
+		}
 		switch key {
 		case "directory":
 			db.Directory = expandHome(value)
@@ -213,6 +224,12 @@ func parseDirbrowser(name string, data map[string]string) Dirbrowser {
 			db.SortDirection = value
 		case "glob":
 			db.Glob = value
+		case "follow_symlinks":
+			db.FollowSymlinks = value == "true"
+		case "exclude":
+			db.Exclude = splitList(value)
+		case "respect_gitignore":
+			db.RespectGitignore = value == "true"
 		case "width":
 			db.Width = value
 		case "height":
@@ -234,8 +251,12 @@ func parseTaskrunner(name string, data map[string]string) TaskrunnerConfig {
 	tr := DefaultTaskrunner()
 	tr.Name = name
 	tr.Label = name // Default label is the name
+	tr.Options = make(map[string]string)
 
 	for key, value := range data {
+		if parseHook(&tr.Hooks, key, value) {
+			continue
+		}
 		switch key {
 		case "enabled":
 			tr.Enabled = value == "true"
@@ -247,12 +268,100 @@ func parseTaskrunner(name string, data map[string]string) TaskrunnerConfig {
 			tr.PrimaryAction = Action(value)
 		case "secondary_action":
 			tr.SecondaryAction = Action(value)
+		case "include":
+			tr.Include = splitList(value)
+		case "display":
+			tr.Display = value
+		case "exclude":
+			tr.Exclude = splitList(value)
+		case "max_items":
+			tr.MaxItems, _ = strconv.Atoi(value)
+		default:
+			tr.Options[key] = value
 		}
 	}
 	return tr
 }
 
+func parseTaskOverride(runner, task string, data map[string]string) TaskOverride {
+	o := TaskOverride{Runner: runner, Task: task}
+	for key, value := range data {
+		switch key {
+		case "label":
+			o.Label = value
+		case "desc":
+			o.Desc = value
+		case "primary_action":
+			o.PrimaryAction = Action(value)
+		case "secondary_action":
+			o.SecondaryAction = Action(value)
+		case "confirm":
+			o.Confirm = value == "true"
+		case "shortcut":
+			o.Shortcut = value
+		}
+	}
+	return o
+}
+
+func parsePipeline(name string, data map[string]string) Pipeline {
+	p := Pipeline{
+		Name: name,
+		Mode: PipelineSequential,
+	}
+	for key, value := range data {
+		if parseHook(&p.Hooks, key, value) {
+			continue
+		}
+		switch key {
+		case "desc":
+			p.Desc = value
+		case "steps":
+			p.Steps = splitList(value)
+		case "mode":
+			p.Mode = value
+		case "primary_action":
+			p.PrimaryAction = Action(value)
+		case "secondary_action":
+			p.SecondaryAction = Action(value)
+		}
+	}
+	return p
+}
+
+// splitList splits a comma-separated list, dropping empty entries
+func splitList(value string) []string {
+	var list []string
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			list = append(list, entry)
+		}
+	}
+	return list
+}
+
+// parseHook sets a hook from a pre_launch, on_success, on_fail or on_exit
+// key, reporting whether key was a hook
+func parseHook(h *Hooks, key, value string) bool {
+	switch key {
+	case "pre_launch":
+		h.PreLaunch = value
+	case "on_success":
+		h.OnSuccess = value
+	case "on_fail":
+		h.OnFail = value
+	case "on_exit":
+		h.OnExit = value
+	default:
+		return false
+	}
+	return true
+}
+
 func applySettings(s *Settings, key, value string) {
+	if parseHook(&s.Hooks, key, value) {
+		return
+	}
 	switch key {
 	case "icon_running":
 		s.IconRunning = value
@@ -300,6 +409,12 @@ func applySettings(s *Settings, key, value string) {
 		s.ActionMenuKey = value
 	case "toggle_shortcuts_key":
 		s.ToggleShortcutsKey = value
+	case "task_history_key":
+		s.TaskHistoryKey = value
+	case "watch_key":
+		s.WatchKey = value
+	case "rerun_key":
+		s.RerunKey = value
 	case "label":
 		s.Label = value
 	case "show_help":
@@ -318,6 +433,12 @@ func applySettings(s *Settings, key, value string) {
 		s.FzfColors = value
 	case "exclude_patterns":
 		s.ExcludePatterns = value
+	case "status_mode":
+		s.StatusMode = value
+	case "status_concurrency":
+		s.StatusConcurrency, _ = strconv.Atoi(value)
+	case "menu_budget_ms":
+		s.MenuBudgetMs, _ = strconv.Atoi(value)
 	}
 }
 
@@ -329,6 +450,16 @@ func applyTaskrunnerGlobalSettings(s *Settings, key, value string) {
 		s.TaskrunnerIconSuccess = value
 	case "icon_failed":
 		s.TaskrunnerIconFailed = value
+	case "icon_watch":
+		s.TaskrunnerIconWatch = value
+	case "notify":
+		s.TaskrunnerNotify = value
+	case "display":
+		s.TaskrunnerDisplay = value
+	case "task_target":
+		s.TaskTarget = value
+	case "task_pane_size":
+		s.TaskPaneSize = value
 	}
 }
 
@@ -354,6 +485,25 @@ func expandHome(path string) string {
 	return path
 }
 
+// CacheDir returns nunchux's cache directory (logs, remembered state)
+func CacheDir() string {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		cacheDir = filepath.Join(os.Getenv("HOME"), ".cache")
+	}
+	return filepath.Join(cacheDir, "nunchux")
+}
+
+// ConfigDir returns the nunchux config directory (~/.config/nunchux)
+func ConfigDir() string {
+	configDir := os.Getenv("XDG_CONFIG_HOME")
+	if configDir == "" {
+		home, _ := os.UserHomeDir()
+		configDir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(configDir, "nunchux")
+}
+
 // FindConfigFile searches for config file in priority order
 func FindConfigFile() (string, error) {
 	// 1. Environment variable
@@ -375,12 +525,7 @@ func FindConfigFile() (string, error) {
 	}
 
 	// 3. XDG config
-	configDir := os.Getenv("XDG_CONFIG_HOME")
-	if configDir == "" {
-		home, _ := os.UserHomeDir()
-		configDir = filepath.Join(home, ".config")
-	}
-	xdgConfig := filepath.Join(configDir, "nunchux", "config")
+	xdgConfig := filepath.Join(ConfigDir(), "config")
 	if _, err := os.Stat(xdgConfig); err == nil {
 		return xdgConfig, nil
 	}
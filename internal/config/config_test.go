@@ -0,0 +1,89 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// loadString loads a config file with the given contents
+func loadString(t *testing.T, contents string) *Config {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func TestTaskrunnerFilters(t *testing.T) {
+	cfg := loadString(t, `
+[taskrunner:just]
+enabled = true
+include = build*, test
+exclude = _*
+max_items = 5
+
+[taskrunner:make]
+enabled = true
+include = true
+include_makefiles = true
+`)
+	if len(cfg.Taskrunners) != 2 {
+		t.Fatalf("taskrunners = %+v", cfg.Taskrunners)
+	}
+
+	just := cfg.Taskrunners[0]
+	if want := []string{"build*", "test"}; !reflect.DeepEqual(just.Include, want) {
+		t.Errorf("include = %q, want %q", just.Include, want)
+	}
+	if want := []string{"_*"}; !reflect.DeepEqual(just.Exclude, want) {
+		t.Errorf("exclude = %q, want %q", just.Exclude, want)
+	}
+	if just.MaxItems != 5 {
+		t.Errorf("max_items = %d, want 5", just.MaxItems)
+	}
+
+	// include is always a list of globs; make's option has a name of its own
+	mk := cfg.Taskrunners[1]
+	if want := []string{"true"}; !reflect.DeepEqual(mk.Include, want) {
+		t.Errorf("make include = %q, want %q", mk.Include, want)
+	}
+	if mk.Options["include_makefiles"] != "true" {
+		t.Errorf("make options = %v", mk.Options)
+	}
+	if _, ok := mk.Options["include"]; ok {
+		t.Error("include stored as a runner option")
+	}
+}
+
+func TestTaskOverrides(t *testing.T) {
+	cfg := loadString(t, `
+[task:just:deploy]
+label = ship
+desc = Deploy to prod
+primary_action = pane_below
+secondary_action = popup
+confirm = true
+shortcut = alt-d
+
+[task:mytool:web › build]
+confirm = true
+
+[task:nocolon]
+confirm = true
+`)
+	want := []TaskOverride{
+		{Runner: "just", Task: "deploy", Label: "ship", Desc: "Deploy to prod", PrimaryAction: ActionPaneBelow,
+			SecondaryAction: ActionPopup, Confirm: true, Shortcut: "alt-d"},
+		{Runner: "mytool", Task: "web › build", Confirm: true},
+	}
+	if !reflect.DeepEqual(cfg.Tasks, want) {
+		t.Errorf("tasks = %+v, want %+v", cfg.Tasks, want)
+	}
+}
@@ -37,6 +37,9 @@ func DefaultSettings() Settings {
 		PaneBelowKey:        "",
 		ActionMenuKey:       "ctrl-j",
 		ToggleShortcutsKey:  "ctrl-/",
+		TaskHistoryKey:      "",
+		WatchKey:            "",
+		RerunKey:            "",
 
 		// Display
 		Label:    "nunchux",
@@ -53,10 +56,20 @@ func DefaultSettings() Settings {
 		// Exclude patterns for dirbrowser
 		ExcludePatterns: ".git, node_modules, Cache, cache, .cache, GPUCache, CachedData, blob_storage, Code Cache, Session Storage, Local Storage, IndexedDB, databases, *.db, *.db-*, *.sqlite*, *.log, *.png, *.jpg, *.jpeg, *.gif, *.ico, *.webp, *.woff*, *.ttf, *.lock, lock, *.pid",
 
+		// Status commands
+		StatusMode:        "process",
+		StatusConcurrency: 8,
+		MenuBudgetMs:      0, // 0 = wait for every status
+
 		// Taskrunner icons
 		TaskrunnerIconRunning: "🔄",
 		TaskrunnerIconSuccess: "✅",
 		TaskrunnerIconFailed:  "❌",
+		TaskrunnerIconWatch:   "👀",
+		TaskrunnerNotify:      "none",
+		TaskrunnerDisplay:     DisplayInline,
+		TaskTarget:            TaskTargetWindow,
+		TaskPaneSize:          "30%",
 	}
 }
 
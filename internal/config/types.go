@@ -11,15 +11,19 @@ const (
 	ActionPaneLeft         Action = "pane_left"
 	ActionPaneAbove        Action = "pane_above"
 	ActionPaneBelow        Action = "pane_below"
+	ActionWatch            Action = "watch" // Tasks only: window that re-runs on file changes
 )
 
 // Config holds all parsed configuration
 type Config struct {
+	Path        string // File the config was loaded from
 	Settings    Settings
 	Apps        []App
 	Menus       []Menu
 	Dirbrowsers []Dirbrowser
 	Taskrunners []TaskrunnerConfig
+	Tasks       []TaskOverride
+	Pipelines   []Pipeline
 	Order       OrderConfig
 }
 
@@ -59,6 +63,9 @@ type Settings struct {
 	PaneBelowKey        string
 	ActionMenuKey       string
 	ToggleShortcutsKey  string
+	TaskHistoryKey      string
+	WatchKey            string
+	RerunKey            string
 
 	// Display
 	Label    string
@@ -75,6 +82,11 @@ type Settings struct {
 	// Exclude patterns for dirbrowser
 	ExcludePatterns string
 
+	// Status commands
+	StatusMode        string // "process" (one bash per command) or "batch" (one shared bash)
+	StatusConcurrency int    // Max items formatted at once
+	MenuBudgetMs      int    // Total time to wait for statuses (0 = no limit)
+
 	// Runtime - set programmatically, not from config
 	BinDir string // Directory containing helper scripts (lines, ago, nearest)
 
@@ -82,6 +94,50 @@ type Settings struct {
 	TaskrunnerIconRunning string
 	TaskrunnerIconSuccess string
 	TaskrunnerIconFailed  string
+	TaskrunnerIconWatch   string
+
+	// Taskrunner completion notification: "tmux", "bell", "desktop" or "none"
+	TaskrunnerNotify string
+
+	// Where taskrunner tasks are listed: "inline" or "submenu"
+	TaskrunnerDisplay string
+
+	// Where window tasks run: "window" or "pane" (one reused pane per window)
+	TaskTarget   string
+	TaskPaneSize string // Height of the task pane (e.g., "30%", "12")
+
+	// Global hooks, run before item hooks
+	Hooks Hooks
+}
+
+// Hooks are shell commands run around a launch. They run in the launched
+// shell and may use {exit_code}, {name}, {action} and {dir}.
+type Hooks struct {
+	PreLaunch string // Before the command starts
+	OnSuccess string // After the command exits with code 0
+	OnFail    string // After the command exits non-zero
+	OnExit    string // After the command exits, either way
+}
+
+// IsEmpty reports whether no hook is set
+func (h Hooks) IsEmpty() bool {
+	return h == Hooks{}
+}
+
+// Merge returns h followed by item's hooks, so global hooks run first
+func (h Hooks) Merge(item Hooks) Hooks {
+	join := func(a, b string) string {
+		if a == "" || b == "" {
+			return a + b
+		}
+		return a + "\n" + b
+	}
+	return Hooks{
+		PreLaunch: join(h.PreLaunch, item.PreLaunch),
+		OnSuccess: join(h.OnSuccess, item.OnSuccess),
+		OnFail:    join(h.OnFail, item.OnFail),
+		OnExit:    join(h.OnExit, item.OnExit),
+	}
 }
 
 // App represents a configured application
@@ -93,7 +149,7 @@ type App struct {
 	Height          string
 	Status          string // Shell command to get status
 	StatusScript    string // Path to status script
-	OnExit          string // Shell command to run after exit
+	Hooks           Hooks
 	Shortcut        string
 	PrimaryAction   Action
 	SecondaryAction Action
@@ -111,18 +167,22 @@ type Menu struct {
 
 // Dirbrowser represents a directory browser configuration
 type Dirbrowser struct {
-	Name            string
-	Directory       string
-	Depth           int
-	Sort            string // "modified", "modified-folder", "alphabetical"
-	SortDirection   string // "ascending", "descending"
-	Glob            string
-	Width           string
-	Height          string
-	CacheTTL        int
-	Shortcut        string
-	PrimaryAction   Action
-	SecondaryAction Action
+	Name             string
+	Directory        string
+	Depth            int
+	Sort             string // "modified", "modified-folder", "alphabetical"
+	SortDirection    string // "ascending", "descending"
+	Glob             string
+	FollowSymlinks   bool     // List linked files and walk into linked directories
+	Exclude          []string // Patterns added to exclude_patterns ("!" re-includes)
+	RespectGitignore bool     // Skip what .gitignore, .ignore and git's excludes ignore
+	Width            string
+	Height           string
+	CacheTTL         int
+	Shortcut         string
+	PrimaryAction    Action
+	SecondaryAction  Action
+	Hooks            Hooks
 }
 
 // TaskrunnerConfig represents taskrunner settings
@@ -133,6 +193,53 @@ type TaskrunnerConfig struct {
 	Label           string
 	PrimaryAction   Action
 	SecondaryAction Action
+	Hooks           Hooks
+	Display         string            // "inline" or "submenu" (empty = the global setting)
+	Include         []string          // Globs of task names to show (empty = all)
+	Exclude         []string          // Globs of task names to hide
+	MaxItems        int               // Maximum number of tasks shown (0 = no limit)
+	Options         map[string]string // Provider-specific options (other keys)
+}
+
+// Taskrunner display modes
+const (
+	DisplayInline  = "inline"  // Tasks are listed in the main menu
+	DisplaySubmenu = "submenu" // The main menu has an entry opening the runner's tasks
+)
+
+// Task targets
+const (
+	TaskTargetWindow = "window" // Each task gets its own window
+	TaskTargetPane   = "pane"   // Tasks share one pane below the current pane
+)
+
+// TaskOverride holds the settings of a single task ([task:runner:name])
+type TaskOverride struct {
+	Runner          string
+	Task            string
+	Label           string // Shown instead of the task name
+	Desc            string
+	PrimaryAction   Action
+	SecondaryAction Action
+	Confirm         bool // Ask before running
+	Shortcut        string
+}
+
+// Pipeline modes
+const (
+	PipelineSequential = "sequential" // One step after another, stopping on failure
+	PipelineParallel   = "parallel"   // All steps at once, in panes of one window
+)
+
+// Pipeline is a list of steps run as one task
+type Pipeline struct {
+	Name            string
+	Desc            string
+	Steps           []string // "runner:task" or "cmd:<shell command>"
+	Mode            string
+	PrimaryAction   Action
+	SecondaryAction Action
+	Hooks           Hooks
 }
 
 // OrderConfig holds ordering configuration
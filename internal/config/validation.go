@@ -54,6 +54,15 @@ func GetReservedKeys(settings *Settings) map[string]string {
 	if settings.ToggleShortcutsKey != "" {
 		reserved[settings.ToggleShortcutsKey] = "toggle_shortcuts_key"
 	}
+	if settings.TaskHistoryKey != "" {
+		reserved[settings.TaskHistoryKey] = "task_history_key"
+	}
+	if settings.WatchKey != "" {
+		reserved[settings.WatchKey] = "watch_key"
+	}
+	if settings.RerunKey != "" {
+		reserved[settings.RerunKey] = "rerun_key"
+	}
 	if settings.PopupKey != "" {
 		reserved[settings.PopupKey] = "popup_key"
 	}
@@ -0,0 +1,134 @@
+package daemon
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"time"
+
+	"nunchux/internal/launch"
+)
+
+// dialTimeout keeps a dead socket from delaying the popup
+const dialTimeout = 50 * time.Millisecond
+
+// callTimeout bounds a whole request, including a cold render. A daemon
+// slower than this loses to the in-process fallback anyway.
+const callTimeout = 500 * time.Millisecond
+
+// ErrDisabled is returned when NUNCHUX_NO_DAEMON is set
+var ErrDisabled = errors.New("daemon disabled by NUNCHUX_NO_DAEMON")
+
+// Call sends a single request to the daemon and decodes the result
+func Call(method string, params, result any) error {
+	if os.Getenv("NUNCHUX_NO_DAEMON") != "" {
+		return ErrDisabled
+	}
+
+	conn, err := net.DialTimeout("unix", SocketPath(), dialTimeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(callTimeout))
+
+	req := Request{JSONRPC: "2.0", ID: 1, Method: method}
+	if params != nil {
+		if req.Params, err = json.Marshal(params); err != nil {
+			return err
+		}
+	}
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return err
+	}
+
+	var resp Response
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return err
+	}
+	if resp.Error != nil {
+		return resp.Error
+	}
+	if result != nil && len(resp.Result) > 0 {
+		return json.Unmarshal(resp.Result, result)
+	}
+	return nil
+}
+
+// IsRunning reports whether a daemon is listening on the socket
+func IsRunning() bool {
+	conn, err := net.DialTimeout("unix", SocketPath(), dialTimeout)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// Publish forwards a launch event to the daemon's subscribers. It is best
+// effort: without a daemon the event is dropped.
+func Publish(event launch.Event) {
+	Call(MethodEventsPublish, event, nil)
+}
+
+// Publisher returns Publish when a daemon socket exists, and nil otherwise,
+// so launches without a daemon do not dial for nothing
+func Publisher() func(launch.Event) {
+	if os.Getenv("NUNCHUX_NO_DAEMON") != "" {
+		return nil
+	}
+	if _, err := os.Stat(SocketPath()); err != nil {
+		return nil
+	}
+	return Publish
+}
+
+// Subscribe streams launch events to fn until the connection closes
+func Subscribe(fn func(launch.Event)) error {
+	if os.Getenv("NUNCHUX_NO_DAEMON") != "" {
+		return ErrDisabled
+	}
+
+	conn, err := net.DialTimeout("unix", SocketPath(), dialTimeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	req := Request{JSONRPC: "2.0", ID: 1, Method: MethodEventsSubscribe}
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(conn)
+	var resp Response
+	if err := decoder.Decode(&resp); err != nil {
+		return err
+	}
+	if resp.Error != nil {
+		return resp.Error
+	}
+
+	for {
+		var note struct {
+			Method string       `json:"method"`
+			Params launch.Event `json:"params"`
+		}
+		if err := decoder.Decode(&note); err != nil {
+			return err
+		}
+		if note.Method == MethodEvent {
+			fn(note.Params)
+		}
+	}
+}
+
+// RenderMenu asks the daemon for menu content
+func RenderMenu(params MenuParams) (string, error) {
+	var result MenuResult
+	if err := Call(MethodMenuRender, params, &result); err != nil {
+		return "", err
+	}
+	return result.Content, nil
+}
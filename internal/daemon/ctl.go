@@ -0,0 +1,177 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"nunchux/internal/items"
+	"nunchux/internal/launch"
+	"nunchux/internal/tmux"
+)
+
+// Control API: list, launch and kill items from scripts and editors
+
+// withEntry runs fn on the refreshed warm registry for a control request
+func (s *Server) withEntry(ctx context.Context, c Context, fn func(*items.Registry, *launch.Launcher) error) error {
+	if c.Config == "" || c.Dir == "" {
+		return fmt.Errorf("config and dir are required")
+	}
+
+	e := s.entry(entryKey{config: c.Config, dir: c.Dir})
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := s.refresh(ctx, e); err != nil {
+		return err
+	}
+
+	tmuxClient := tmux.NewClient(s.BinDir)
+	if c.Pane != "" {
+		tmuxClient = tmuxClient.WithTarget(c.Pane)
+	}
+	launcher := launch.New(e.registry, tmuxClient)
+	launcher.Publish = s.publish
+	return fn(e.registry, launcher)
+}
+
+func (s *Server) listItems(ctx context.Context, c Context) ([]ItemInfo, error) {
+	var list []ItemInfo
+	err := s.withEntry(ctx, c, func(r *items.Registry, l *launch.Launcher) error {
+		running := l.Tmux.RunningWindows()
+
+		for _, item := range r.Items {
+			info := ItemInfo{
+				Name:     item.Name(),
+				Running:  running[item.Name()],
+				Shortcut: item.Shortcut(),
+				Parent:   item.Parent(),
+			}
+			switch it := item.(type) {
+			case *items.AppItem:
+				info.Type = "app"
+				info.Desc = it.App.Desc
+				info.Status = it.StatusText(ctx)
+			case *items.MenuItem:
+				info.Type = "menu"
+				info.Desc = it.Menu.Desc
+				info.Status = it.StatusText(ctx)
+			case *items.DirbrowserItem:
+				info.Type = "dirbrowser"
+				info.Desc = it.Dirbrowser.Directory
+			}
+			list = append(list, info)
+		}
+
+		for _, item := range r.TaskrunnerItems {
+			switch it := item.(type) {
+			case *items.TaskrunnerItem:
+				list = append(list, ItemInfo{
+					Name:     it.Name(),
+					Type:     "task",
+					Desc:     it.Description(),
+					Shortcut: it.Shortcut(),
+					Running:  running[it.WindowName()],
+				})
+			case *items.PipelineItem:
+				list = append(list, ItemInfo{
+					Name:    it.Name(),
+					Type:    "pipeline",
+					Desc:    it.Pipeline.Desc,
+					Running: running[it.WindowName()],
+				})
+			}
+		}
+		return nil
+	})
+	return list, err
+}
+
+func (s *Server) launchItem(ctx context.Context, p ItemParams) error {
+	return s.withEntry(ctx, p.Context, func(r *items.Registry, l *launch.Launcher) error {
+		if tr := r.FindTaskrunnerItem(p.Name); tr != nil {
+			return l.Task(tr, p.Action, p.Dir, p.Args)
+		}
+		if pipeline := r.FindPipeline(p.Name); pipeline != nil {
+			return l.Pipeline(pipeline, p.Action, p.Dir)
+		}
+
+		switch item := r.FindItem(p.Name).(type) {
+		case *items.AppItem:
+			return l.App(item, p.Action, p.Dir)
+		case nil:
+			return fmt.Errorf("item not found: %s", p.Name)
+		default:
+			return fmt.Errorf("%s can only be opened from the menu", p.Name)
+		}
+	})
+}
+
+func (s *Server) runTask(ctx context.Context, p ItemParams) error {
+	return s.withEntry(ctx, p.Context, func(r *items.Registry, l *launch.Launcher) error {
+		tr := r.FindTaskrunnerItem(p.Name)
+		if tr == nil {
+			return fmt.Errorf("task not found: %s", p.Name)
+		}
+		return l.Task(tr, p.Action, p.Dir, p.Args)
+	})
+}
+
+func (s *Server) killItem(ctx context.Context, p ItemParams) error {
+	return s.withEntry(ctx, p.Context, func(r *items.Registry, l *launch.Launcher) error {
+		return l.Kill(p.Name)
+	})
+}
+
+// publish sends an event to every subscriber without blocking on slow ones
+func (s *Server) publish(event launch.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for ch := range s.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+func (s *Server) subscribe() chan launch.Event {
+	ch := make(chan launch.Event, 16)
+	s.mu.Lock()
+	s.subscribers[ch] = struct{}{}
+	s.mu.Unlock()
+	return ch
+}
+
+func (s *Server) unsubscribe(ch chan launch.Event) {
+	s.mu.Lock()
+	delete(s.subscribers, ch)
+	s.mu.Unlock()
+}
+
+// streamEvents writes events to a subscribed connection until it closes
+func (s *Server) streamEvents(ctx context.Context, encoder *json.Encoder, decoder *json.Decoder) {
+	ch := s.subscribe()
+	defer s.unsubscribe(ch)
+
+	// The client sends nothing more; a read error means it went away
+	closed := make(chan struct{})
+	go func() {
+		var discard json.RawMessage
+		for decoder.Decode(&discard) == nil {
+		}
+		close(closed)
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-closed:
+			return
+		case event := <-ch:
+			if err := encoder.Encode(Notification{JSONRPC: "2.0", Method: MethodEvent, Params: event}); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package daemon
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"nunchux/internal/config"
+	"nunchux/internal/launch"
+)
+
+// startServer runs a daemon on a temp socket for the test, and returns a
+// config file for it
+func startServer(t *testing.T) (*Server, string) {
+	t.Helper()
+	testSocket(t)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	configPath := filepath.Join(t.TempDir(), "config")
+	content := "[app:htop]\ncmd = sleep 60\ndesc = Processes\nshortcut = ctrl-p\n\n[dirbrowser:notes]\ndirectory = /tmp\n"
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewServer("")
+	if err := s.Listen(); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.Serve(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Error(err)
+		}
+	})
+	return s, configPath
+}
+
+// testTmux starts a private tmux server for the test
+func testTmux(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux not installed")
+	}
+	t.Setenv("TMUX", "")
+	t.Setenv("TMUX_TMPDIR", t.TempDir())
+	if out, err := exec.Command("tmux", "-f", "/dev/null", "new-session", "-d", "-s", "test").CombinedOutput(); err != nil {
+		t.Skipf("cannot start tmux: %v: %s", err, out)
+	}
+	t.Cleanup(func() { exec.Command("tmux", "kill-server").Run() })
+}
+
+func TestItemsList(t *testing.T) {
+	_, configPath := startServer(t)
+
+	var list []ItemInfo
+	if err := Call(MethodItemsList, Context{Config: configPath, Dir: t.TempDir()}, &list); err != nil {
+		t.Fatal(err)
+	}
+	want := []ItemInfo{
+		{Name: "htop", Type: "app", Desc: "Processes", Shortcut: "ctrl-p"},
+		{Name: "notes", Type: "dirbrowser", Desc: "/tmp"},
+	}
+	if len(list) != len(want) {
+		t.Fatalf("items.list = %+v, want %+v", list, want)
+	}
+	for i := range want {
+		if list[i] != want[i] {
+			t.Errorf("item %d = %+v, want %+v", i, list[i], want[i])
+		}
+	}
+
+	if err := Call(MethodItemsList, Context{Dir: "/"}, &list); err == nil {
+		t.Error("items.list without a config succeeded")
+	}
+	if err := Call("no.such.method", nil, nil); err == nil || !strings.Contains(err.Error(), "unknown method") {
+		t.Errorf("unknown method error = %v", err)
+	}
+}
+
+func TestItemsLaunchEvents(t *testing.T) {
+	s, configPath := startServer(t)
+	testTmux(t)
+	dir := t.TempDir()
+
+	events := make(chan launch.Event, 4)
+	go Subscribe(func(e launch.Event) { events <- e })
+	for deadline := time.Now().Add(2 * time.Second); ; {
+		s.mu.Lock()
+		n := len(s.subscribers)
+		s.mu.Unlock()
+		if n > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("subscriber never registered")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	params := ItemParams{Context: Context{Config: configPath, Dir: dir}, Name: "htop", Action: config.ActionBackgroundWindow}
+	if err := Call(MethodItemsLaunch, params, nil); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case e := <-events:
+		if e.Type != "launch" || e.Name != "htop" || e.Kind != "app" || e.Action != config.ActionBackgroundWindow || e.Dir != dir {
+			t.Errorf("event = %+v", e)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no launch event")
+	}
+	if out, _ := exec.Command("tmux", "list-windows", "-a", "-F", "#{window_name}").Output(); !strings.Contains(string(out), "htop") {
+		t.Errorf("no htop window, windows:\n%s", out)
+	}
+
+	// Events published by other processes reach subscribers too
+	Publish(launch.Event{Type: "kill", Name: "other", Kind: "task"})
+	select {
+	case e := <-events:
+		if e.Type != "kill" || e.Name != "other" {
+			t.Errorf("published event = %+v", e)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("published event not streamed")
+	}
+
+	for name, want := range map[string]string{"missing": "item not found", "notes": "only be opened from the menu"} {
+		params.Name = name
+		if err := Call(MethodItemsLaunch, params, nil); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("launch %s error = %v, want %q", name, err, want)
+		}
+	}
+}
+
+func TestPublisher(t *testing.T) {
+	testSocket(t)
+	if Publisher() != nil {
+		t.Error("Publisher without a socket should be nil")
+	}
+	startServer(t)
+	if Publisher() == nil {
+		t.Error("Publisher with a socket should not be nil")
+	}
+}
@@ -0,0 +1,120 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"nunchux/internal/config"
+)
+
+// Requests and responses are JSON-RPC 2.0 objects, one per line
+
+// Request is a JSON-RPC request
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      int             `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// Response is a JSON-RPC response
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      int             `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Error is a JSON-RPC error object
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("daemon error %d: %s", e.Code, e.Message)
+}
+
+// JSON-RPC error codes
+const (
+	CodeParseError     = -32700
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+)
+
+// Methods
+const (
+	MethodMenuRender      = "menu.render"
+	MethodShutdown        = "daemon.shutdown"
+	MethodItemsList       = "items.list"
+	MethodItemsLaunch     = "items.launch"
+	MethodItemsKill       = "items.kill"
+	MethodTasksRun        = "tasks.run"
+	MethodEventsSubscribe = "events.subscribe"
+	MethodEventsPublish   = "events.publish"
+)
+
+// MethodEvent is the notification method used to stream events
+const MethodEvent = "event"
+
+// MenuParams asks for the menu content of a config in a directory
+type MenuParams struct {
+	Config   string   `json:"config"`    // Config file path
+	Dir      string   `json:"dir"`       // Pane working directory
+	Submenu  string   `json:"submenu"`   // Empty for the main menu
+	ShowHelp bool     `json:"show_help"` // Show shortcut prefixes
+	Running  []string `json:"running"`   // Names of running windows
+}
+
+// MenuResult is the rendered menu content for fzf
+type MenuResult struct {
+	Content string `json:"content"`
+}
+
+// Notification is a JSON-RPC request without an id, sent to subscribers
+type Notification struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  any    `json:"params"`
+}
+
+// Context identifies the config, directory and tmux pane a control request
+// acts on
+type Context struct {
+	Config string `json:"config"`         // Config file path
+	Dir    string `json:"dir"`            // Working directory for launches and tasks
+	Pane   string `json:"pane,omitempty"` // tmux pane to act relative to ($TMUX_PANE)
+}
+
+// ItemParams names an item, with an optional action
+type ItemParams struct {
+	Context
+	Name   string        `json:"name"`
+	Action config.Action `json:"action,omitempty"`
+	Args   []string      `json:"args,omitempty"` // Task parameter values
+}
+
+// ItemInfo describes an item for items.list
+type ItemInfo struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"` // "app", "menu", "dirbrowser" or "task"
+	Desc     string `json:"desc,omitempty"`
+	Status   string `json:"status,omitempty"`
+	Running  bool   `json:"running"`
+	Shortcut string `json:"shortcut,omitempty"`
+	Parent   string `json:"parent,omitempty"`
+}
+
+// SocketPath returns the per-user daemon socket path
+func SocketPath() string {
+	if path := os.Getenv("NUNCHUX_SOCKET"); path != "" {
+		return path
+	}
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "nunchux", "daemon.sock")
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("nunchux-%d", os.Getuid()), "daemon.sock")
+}
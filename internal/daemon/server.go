@@ -0,0 +1,386 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"maps"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"nunchux/internal/config"
+	"nunchux/internal/items"
+	"nunchux/internal/launch"
+)
+
+const (
+	// pollInterval is how often watched files are checked and menus re-warmed
+	pollInterval = 2 * time.Second
+
+	// idleTimeout evicts menus for directories nobody has asked about
+	idleTimeout = 30 * time.Minute
+)
+
+// Server keeps menus pre-rendered per (config, directory) and serves them
+// to popup clients over a unix socket
+type Server struct {
+	BinDir string
+
+	now func() time.Time // Clock, replaced in tests
+
+	mu          sync.Mutex
+	entries     map[entryKey]*entry
+	subscribers map[chan launch.Event]struct{}
+	listener    net.Listener
+	cancel      context.CancelFunc
+}
+
+type entryKey struct {
+	config string
+	dir    string
+}
+
+// entry is a warm registry for one config file and working directory
+type entry struct {
+	mu          sync.Mutex
+	key         entryKey
+	registry    *items.Registry
+	configMod   time.Time
+	projectSig  string
+	tasksLoaded bool
+	running     map[string]bool
+	lastUsed    time.Time // Guarded by Server.mu
+
+	// The main menu as last built, and what it was built with
+	menu        string
+	menuRunning map[string]bool
+	menuHelp    bool
+}
+
+// NewServer creates a daemon server
+func NewServer(binDir string) *Server {
+	return &Server{
+		BinDir:      binDir,
+		now:         time.Now,
+		entries:     make(map[entryKey]*entry),
+		subscribers: make(map[chan launch.Event]struct{}),
+	}
+}
+
+// Listen creates the socket, refusing to start if another daemon answers
+func (s *Server) Listen() error {
+	path := SocketPath()
+	if IsRunning() {
+		return fmt.Errorf("daemon already running on %s", path)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	os.Remove(path) // Stale socket from a daemon that died
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return err
+	}
+	os.Chmod(path, 0600)
+	s.listener = listener
+	return nil
+}
+
+// Serve accepts connections until ctx is done or Shutdown is called
+func (s *Server) Serve(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	s.mu.Lock()
+	s.cancel = cancel
+	s.mu.Unlock()
+	defer cancel()
+
+	go s.watch(ctx)
+	go func() {
+		<-ctx.Done()
+		s.listener.Close()
+	}()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				s.closeEntries()
+				os.Remove(SocketPath())
+				return nil
+			}
+			return err
+		}
+		go s.handleConn(ctx, conn)
+	}
+}
+
+// Shutdown stops Serve
+func (s *Server) Shutdown() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		s.cancel()
+	}
+}
+
+func (s *Server) handleConn(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+
+	decoder := json.NewDecoder(conn)
+	encoder := json.NewEncoder(conn)
+	for {
+		var req Request
+		if err := decoder.Decode(&req); err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				encoder.Encode(Response{JSONRPC: "2.0", Error: &Error{Code: CodeParseError, Message: err.Error()}})
+			}
+			return
+		}
+
+		if req.Method == MethodEventsSubscribe {
+			encoder.Encode(Response{JSONRPC: "2.0", ID: req.ID, Result: json.RawMessage(`{}`)})
+			s.streamEvents(ctx, encoder, decoder)
+			return
+		}
+
+		resp := Response{JSONRPC: "2.0", ID: req.ID}
+		result, rpcErr := s.dispatch(ctx, req)
+		if rpcErr != nil {
+			resp.Error = rpcErr
+		} else if result != nil {
+			data, err := json.Marshal(result)
+			if err != nil {
+				resp.Error = &Error{Code: CodeInternalError, Message: err.Error()}
+			} else {
+				resp.Result = data
+			}
+		}
+		if err := encoder.Encode(resp); err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) dispatch(ctx context.Context, req Request) (any, *Error) {
+	switch req.Method {
+	case MethodMenuRender:
+		var params MenuParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &Error{Code: CodeInvalidParams, Message: err.Error()}
+		}
+		content, err := s.renderMenu(ctx, params)
+		if err != nil {
+			return nil, &Error{Code: CodeInternalError, Message: err.Error()}
+		}
+		return MenuResult{Content: content}, nil
+
+	case MethodShutdown:
+		s.Shutdown()
+		return struct{}{}, nil
+
+	case MethodItemsList:
+		var params Context
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &Error{Code: CodeInvalidParams, Message: err.Error()}
+		}
+		list, err := s.listItems(ctx, params)
+		if err != nil {
+			return nil, &Error{Code: CodeInternalError, Message: err.Error()}
+		}
+		return list, nil
+
+	case MethodItemsLaunch, MethodItemsKill, MethodTasksRun:
+		var params ItemParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &Error{Code: CodeInvalidParams, Message: err.Error()}
+		}
+		var err error
+		switch req.Method {
+		case MethodItemsLaunch:
+			err = s.launchItem(ctx, params)
+		case MethodItemsKill:
+			err = s.killItem(ctx, params)
+		case MethodTasksRun:
+			err = s.runTask(ctx, params)
+		}
+		if err != nil {
+			return nil, &Error{Code: CodeInternalError, Message: err.Error()}
+		}
+		return struct{}{}, nil
+
+	case MethodEventsPublish:
+		var event launch.Event
+		if err := json.Unmarshal(req.Params, &event); err != nil {
+			return nil, &Error{Code: CodeInvalidParams, Message: err.Error()}
+		}
+		s.publish(event)
+		return struct{}{}, nil
+	}
+
+	return nil, &Error{Code: CodeMethodNotFound, Message: "unknown method: " + req.Method}
+}
+
+// renderMenu renders a menu from the warm registry for the request
+func (s *Server) renderMenu(ctx context.Context, params MenuParams) (string, error) {
+	e := s.entry(entryKey{config: params.Config, dir: params.Dir})
+
+	running := make(map[string]bool, len(params.Running))
+	for _, name := range params.Running {
+		running[name] = true
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := s.refresh(ctx, e); err != nil {
+		return "", err
+	}
+	e.running = running
+	s.touch(e)
+	e.registry.Settings.ShowHelp = params.ShowHelp
+	if params.Submenu != "" {
+		return e.registry.BuildMenu(ctx, running, params.Submenu), nil
+	}
+	if e.menu == "" || e.menuHelp != params.ShowHelp || !maps.Equal(e.menuRunning, running) {
+		e.buildMenu(ctx)
+	}
+	return e.menu, nil
+}
+
+// buildMenu builds the main menu and caches it. Callers hold e.mu.
+func (e *entry) buildMenu(ctx context.Context) {
+	e.menu = e.registry.BuildMenu(ctx, e.running, "")
+	e.menuRunning = e.running
+	e.menuHelp = e.registry.Settings.ShowHelp
+}
+
+// entry returns the warm entry for key, creating it on first use
+func (s *Server) entry(key entryKey) *entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[key]
+	if !ok {
+		e = &entry{key: key, lastUsed: s.now()}
+		s.entries[key] = e
+	}
+	return e
+}
+
+// touch marks an entry as just used
+func (s *Server) touch(e *entry) {
+	s.mu.Lock()
+	e.lastUsed = s.now()
+	s.mu.Unlock()
+}
+
+// refresh rebuilds the registry when the config changed and reloads
+// taskrunners when a project file changed. Callers hold e.mu.
+func (s *Server) refresh(ctx context.Context, e *entry) error {
+	info, err := os.Stat(e.key.config)
+	if err != nil {
+		return err
+	}
+
+	if e.registry == nil || !info.ModTime().Equal(e.configMod) {
+		cfg, err := config.Load(e.key.config)
+		if err != nil {
+			return err
+		}
+		cfg.Settings.BinDir = s.BinDir
+
+		if e.registry != nil {
+			e.registry.Close()
+		}
+		r := items.NewRegistry(cfg)
+		r.Dir = e.key.dir
+		r.Close() // Replace the default runner with a cached one in the pane dir
+		ttl := time.Duration(cfg.Settings.CacheTTL) * time.Second
+		// Status commands see the daemon's environment, not the client's
+		r.SetStatusRunner(items.NewCachedStatusRunner(items.NewStatusRunner(r.Settings, e.key.dir), ttl))
+		for _, item := range r.Items {
+			if db, ok := item.(*items.DirbrowserItem); ok {
+				db.CacheCount = true
+			}
+		}
+
+		e.registry = r
+		e.configMod = info.ModTime()
+		e.tasksLoaded = false
+		e.menu = ""
+		log.Printf("[INFO] daemon: loaded %s for %s", e.key.config, e.key.dir)
+	}
+
+	if sig := items.TaskrunnerSignature(e.key.dir); !e.tasksLoaded || sig != e.projectSig {
+		e.registry.LoadTaskrunners(ctx)
+		e.projectSig = sig
+		e.tasksLoaded = true
+		e.menu = ""
+	}
+	return nil
+}
+
+// watch polls config and project files, reloading changed entries and
+// rebuilding the main menu, so stale statuses refresh in the background and
+// the next popup is served the result
+func (s *Server) watch(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for _, e := range s.evictIdle() {
+			e.mu.Lock()
+			if err := s.refresh(ctx, e); err != nil {
+				log.Printf("[ERROR] daemon: refresh %s: %v", e.key.dir, err)
+			} else {
+				e.buildMenu(ctx)
+			}
+			e.mu.Unlock()
+		}
+	}
+}
+
+// evictIdle closes and removes the entries unused for idleTimeout, and
+// returns the others
+func (s *Server) evictIdle() []*entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var entries []*entry
+	for key, e := range s.entries {
+		if s.now().Sub(e.lastUsed) > idleTimeout {
+			delete(s.entries, key)
+			go e.close()
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func (e *entry) close() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.registry != nil {
+		e.registry.Close()
+	}
+}
+
+func (s *Server) closeEntries() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range s.entries {
+		e.close()
+	}
+}
@@ -0,0 +1,99 @@
+package daemon
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testSocket points the daemon socket at a temp dir for the test
+func testSocket(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "d.sock")
+	t.Setenv("NUNCHUX_SOCKET", path)
+	t.Setenv("NUNCHUX_NO_DAEMON", "")
+	return path
+}
+
+func TestEvictIdle(t *testing.T) {
+	s := NewServer("")
+	now := time.Unix(1000, 0)
+	s.now = func() time.Time { return now }
+
+	idle := s.entry(entryKey{config: "c", dir: "/idle"})
+	used := s.entry(entryKey{config: "c", dir: "/used"})
+
+	now = now.Add(idleTimeout - time.Minute)
+	s.touch(used)
+	if got := s.evictIdle(); len(got) != 2 {
+		t.Fatalf("evicted before idleTimeout: %d entries left", len(got))
+	}
+
+	now = now.Add(2 * time.Minute)
+	got := s.evictIdle()
+	if len(got) != 1 || got[0] != used {
+		t.Fatalf("evictIdle kept %v, want only the used entry", got)
+	}
+	if _, ok := s.entries[idle.key]; ok {
+		t.Error("idle entry still in the server")
+	}
+}
+
+func TestCallTimeout(t *testing.T) {
+	listener, err := net.Listen("unix", testSocket(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	// A hung daemon: accepts and never answers
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	start := time.Now()
+	if _, err := RenderMenu(MenuParams{Config: "c", Dir: "/"}); err == nil {
+		t.Fatal("RenderMenu from a hung daemon succeeded")
+	}
+	if elapsed := time.Since(start); elapsed > callTimeout+time.Second {
+		t.Errorf("RenderMenu took %v, want about %v", elapsed, callTimeout)
+	}
+}
+
+func TestRenderMenuServesCachedMenu(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config")
+	if err := os.WriteFile(configPath, []byte("[app:a]\ncmd = true\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewServer("")
+	defer s.closeEntries()
+	params := MenuParams{Config: configPath, Dir: dir}
+	content, err := s.renderMenu(context.Background(), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Stand in for a menu built by the watch loop
+	e := s.entry(entryKey{config: configPath, dir: dir})
+	e.menu = "cached"
+	if got, _ := s.renderMenu(context.Background(), params); got != "cached" {
+		t.Errorf("menu = %q, want the cached menu", got)
+	}
+
+	// A window started since: the cached menu is stale
+	params.Running = []string{"a"}
+	if got, _ := s.renderMenu(context.Background(), params); got == "cached" || got == content {
+		t.Errorf("menu = %q, want it rebuilt with a running", got)
+	}
+}
@@ -154,3 +154,59 @@ func BuildForActionMenu(settings *config.Settings, itemName string) []string {
 		"--expect=enter,esc",
 	}
 }
+
+// BuildForConfirm returns options for a yes/no question
+func BuildForConfirm(settings *config.Settings, question string) []string {
+	return []string{
+		"--ansi",
+		"--delimiter=\t",
+		"--with-nth=2",
+		"--height=100%",
+		"--layout=reverse",
+		"--border=rounded",
+		"--border-label= " + question + " ",
+		"--border-label-pos=3",
+		"--no-info",
+		"--pointer=" + settings.FzfPointer,
+		"--color=" + settings.FzfColors,
+		"--expect=enter,esc",
+	}
+}
+
+// BuildForInput returns options for a single-line input prompt
+func BuildForInput(settings *config.Settings, label, prompt, query, header string) []string {
+	return []string{
+		"--height=100%",
+		"--layout=reverse",
+		"--border=rounded",
+		"--border-label= " + label + " ",
+		"--border-label-pos=3",
+		"--no-info",
+		"--no-separator",
+		"--prompt=" + prompt,
+		"--query=" + query,
+		"--header=" + header,
+		"--color=" + settings.FzfColors,
+	}
+}
+
+// BuildForHistory returns options for the task history list. Lines are
+// "display\tindex"; expectKeys are returned as the pressed key.
+func BuildForHistory(settings *config.Settings, header string, expectKeys ...string) []string {
+	return []string{
+		"--ansi",
+		"--delimiter=\t",
+		"--with-nth=1",
+		"--height=100%",
+		"--layout=reverse",
+		"--border=" + settings.FzfBorder,
+		"--border-label= " + settings.Label + ": task history ",
+		"--border-label-pos=3",
+		"--no-sort",
+		"--pointer=" + settings.FzfPointer,
+		"--color=" + settings.FzfColors,
+		"--header=" + header,
+		"--header-first",
+		"--expect=" + strings.Join(append([]string{"enter", "esc"}, expectKeys...), ","),
+	}
+}
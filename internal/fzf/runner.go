@@ -80,3 +80,25 @@ func Version() (string, error) {
 	}
 	return version, nil
 }
+
+// Input shows an fzf prompt with no list and returns the typed query
+func Input(opts []string) (string, bool, error) {
+	cmd := exec.Command("fzf", append(opts, "--print-query")...)
+	cmd.Stdin = strings.NewReader("")
+	cmd.Stderr = os.Stderr
+
+	// With nothing to match, fzf exits 1 but still prints the query
+	output, err := cmd.Output()
+	if err != nil {
+		exitErr, ok := err.(*exec.ExitError)
+		if !ok {
+			return "", false, err
+		}
+		if exitErr.ExitCode() != 1 {
+			return "", false, nil // Canceled
+		}
+	}
+
+	query, _, _ := strings.Cut(string(output), "\n")
+	return query, true, nil
+}
@@ -0,0 +1,217 @@
+// Package history records taskrunner runs: what ran, where, how long it
+// took, how it exited, and a log of its output.
+package history
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+
+	"nunchux/internal/config"
+)
+
+// maxRuns is how many runs are kept; older runs and their logs are removed
+const maxRuns = 500
+
+// Run is one recorded task run
+type Run struct {
+	Name     string        `json:"name"`   // Item name, e.g. "just:build"
+	Runner   string        `json:"runner"` // e.g. "just"
+	Task     string        `json:"task"`   // e.g. "build"
+	Cmd      string        `json:"cmd"`    // Exact command, with arguments
+	Args     []string      `json:"args,omitempty"`
+	Dir      string        `json:"dir"`
+	Action   config.Action `json:"action"`
+	Start    time.Time     `json:"start"`
+	Duration time.Duration `json:"duration"`
+	ExitCode int           `json:"exit_code"`
+	Log      string        `json:"log"`
+	Client   string        `json:"client,omitempty"` // tty of the tmux client that launched it
+}
+
+// Succeeded reports whether the run exited with code 0
+func (r Run) Succeeded() bool {
+	return r.ExitCode == 0
+}
+
+// Dir returns the history directory
+func Dir() string {
+	return filepath.Join(config.CacheDir(), "history")
+}
+
+func runsPath() string {
+	return filepath.Join(Dir(), "runs.jsonl")
+}
+
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// NewRun starts a run record with a fresh log path, creating the log
+// directory
+func NewRun(name, runner, task, cmd, dir string, action config.Action, args []string) (*Run, error) {
+	start := time.Now()
+	logDir := filepath.Join(Dir(), "logs")
+	if err := os.MkdirAll(logDir, 0755); err != nil {
+		return nil, err
+	}
+	logName := fmt.Sprintf("%d-%s.log", start.UnixNano(), unsafeChars.ReplaceAllString(name, "_"))
+	return &Run{
+		Name:   name,
+		Runner: runner,
+		Task:   task,
+		Cmd:    cmd,
+		Args:   args,
+		Dir:    dir,
+		Action: action,
+		Start:  start,
+		Log:    filepath.Join(logDir, logName),
+	}, nil
+}
+
+// Encode serializes a run for passing to `nunchux task record`
+func (r Run) Encode() string {
+	data, _ := json.Marshal(r)
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+// Decode parses a run serialized by Encode
+func Decode(s string) (Run, error) {
+	var r Run
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return r, err
+	}
+	err = json.Unmarshal(data, &r)
+	return r, err
+}
+
+var mu sync.Mutex
+
+// Record appends a finished run to the history and makes it the last run
+// of its task in its project. The history stays locked from the append to
+// the prune, so a prune never drops another process's run.
+func Record(run Run) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	data, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+	unlock, err := lockPath(runsPath())
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	f, err := os.OpenFile(runsPath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(data, '\n'))
+	f.Close()
+	if err != nil {
+		return err
+	}
+
+	if err := saveLastRun(run); err != nil {
+		return err
+	}
+	return prune()
+}
+
+// Load returns the recorded runs, newest first
+func Load() ([]Run, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	runs, err := readRuns()
+	if err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(runs)-1; i < j; i, j = i+1, j-1 {
+		runs[i], runs[j] = runs[j], runs[i]
+	}
+	return runs, nil
+}
+
+// readRuns reads runs in file order, skipping malformed lines
+func readRuns() ([]Run, error) {
+	f, err := os.Open(runsPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var runs []Run
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var run Run
+		if json.Unmarshal(scanner.Bytes(), &run) == nil {
+			runs = append(runs, run)
+		}
+	}
+	return runs, scanner.Err()
+}
+
+// prune drops the oldest runs and their logs once there are too many. It
+// lets the file grow to twice the limit first, so most records don't
+// rewrite it. The caller holds the history lock.
+func prune() error {
+	runs, err := readRuns()
+	if err != nil || len(runs) <= 2*maxRuns {
+		return err
+	}
+
+	drop, keep := runs[:len(runs)-maxRuns], runs[len(runs)-maxRuns:]
+	for _, run := range drop {
+		os.Remove(run.Log)
+	}
+
+	return writeFileAtomic(runsPath(), func(f *os.File) error {
+		encoder := json.NewEncoder(f)
+		for _, run := range keep {
+			if err := encoder.Encode(run); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Ago formats how long ago t was, e.g. "5m ago"
+func Ago(t time.Time) string {
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	}
+}
+
+// FormatDuration formats a run's duration compactly, e.g. "1.2s" or "3m04s"
+func FormatDuration(d time.Duration) string {
+	switch {
+	case d < time.Second:
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	case d < time.Minute:
+		return fmt.Sprintf("%.1fs", d.Seconds())
+	case d < time.Hour:
+		return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+	default:
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+}
@@ -0,0 +1,201 @@
+package history
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"nunchux/internal/config"
+)
+
+func TestRecordAndLoad(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	for i, name := range []string{"just:build", "just:test"} {
+		run, err := NewRun(name, "just", name[5:], "just "+name[5:], "/src", config.ActionWindow, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if filepath.Dir(run.Log) != filepath.Join(Dir(), "logs") {
+			t.Errorf("log path = %q", run.Log)
+		}
+		run.ExitCode = i
+		if err := Record(*run); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	runs, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 2 || runs[0].Name != "just:test" || runs[1].Name != "just:build" {
+		t.Fatalf("runs = %+v", runs)
+	}
+	if runs[0].Succeeded() || !runs[1].Succeeded() {
+		t.Errorf("exit codes = %d, %d", runs[0].ExitCode, runs[1].ExitCode)
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	run := Run{Name: "npm:dev", Cmd: "npm run 'dev' -- --port 3000", Args: []string{"--port 3000"}, Start: time.Unix(1700000000, 0)}
+	got, err := Decode(run.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Cmd != run.Cmd || !got.Start.Equal(run.Start) || len(got.Args) != 1 {
+		t.Errorf("Decode(Encode()) = %+v", got)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	oldLog := filepath.Join(t.TempDir(), "old.log")
+	os.WriteFile(oldLog, []byte("output"), 0644)
+
+	for i := 0; i <= 2*maxRuns; i++ {
+		run := Run{Name: "make:all", ExitCode: i}
+		if i == 0 {
+			run.Log = oldLog
+		}
+		if err := Record(run); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	runs, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != maxRuns || runs[0].ExitCode != 2*maxRuns {
+		t.Fatalf("kept %d runs, newest exit %d", len(runs), runs[0].ExitCode)
+	}
+	if _, err := os.Stat(oldLog); !os.IsNotExist(err) {
+		t.Error("pruned run's log was not removed")
+	}
+}
+
+func TestLockPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "runs.jsonl")
+	unlock, err := lockPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A second lock, as another process would take, waits for the first
+	locked := make(chan func())
+	go func() {
+		unlock2, err := lockPath(path)
+		if err != nil {
+			t.Error(err)
+			unlock2 = func() {}
+		}
+		locked <- unlock2
+	}()
+	select {
+	case <-locked:
+		t.Fatal("lock taken twice")
+	case <-time.After(50 * time.Millisecond):
+	}
+	unlock()
+	select {
+	case unlock2 := <-locked:
+		unlock2()
+	case <-time.After(time.Second):
+		t.Fatal("lock not released")
+	}
+
+	if err := writeFileAtomic(path, func(f *os.File) error { _, err := f.WriteString("x"); return err }); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 2 {
+		t.Errorf("files left = %v, want the file and its lock", entries)
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := map[time.Duration]string{
+		250 * time.Millisecond:        "250ms",
+		1200 * time.Millisecond:       "1.2s",
+		3*time.Minute + 4*time.Second: "3m04s",
+		2*time.Hour + 5*time.Minute:   "2h05m",
+	}
+	for d, want := range tests {
+		if got := FormatDuration(d); got != want {
+			t.Errorf("FormatDuration(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestLastRuns(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	root := t.TempDir()
+	os.Mkdir(filepath.Join(root, ".git"), 0755)
+	sub := filepath.Join(root, "cmd", "app")
+	os.MkdirAll(sub, 0755)
+
+	Record(Run{Name: "just:test", Dir: root, ExitCode: 0})
+	Record(Run{Name: "just:test", Dir: sub, ExitCode: 2})
+	Record(Run{Name: "just:build", Dir: t.TempDir(), ExitCode: 0})
+
+	last := LastRuns(root)
+	if len(last) != 1 || last["just:test"].ExitCode != 2 {
+		t.Errorf("LastRuns(root) = %+v", last)
+	}
+	if got := LastRuns(sub)["just:test"].Dir; got != sub {
+		t.Errorf("LastRuns(sub) dir = %q, want the project's", got)
+	}
+}
+
+func TestLastLaunch(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	root := t.TempDir()
+	os.Mkdir(filepath.Join(root, ".git"), 0755)
+	sub := filepath.Join(root, "web")
+	os.MkdirAll(sub, 0755)
+
+	if _, ok := LastLaunch(root); ok {
+		t.Fatal("LastLaunch found a launch in a new project")
+	}
+
+	SaveLaunch(Run{Name: "just:test", Dir: root, Action: "window", Args: []string{"./..."}})
+	Record(Run{Name: "just:test", Dir: root})
+	SaveLaunch(Run{Name: "npm:build", Dir: sub, Action: "popup"})
+
+	last, ok := LastLaunch(root)
+	if !ok || last.Name != "npm:build" || last.Dir != sub || last.Action != "popup" {
+		t.Errorf("LastLaunch = %+v, %v", last, ok)
+	}
+	if LastRuns(root)["just:test"].Name != "just:test" {
+		t.Error("saving a launch lost the last runs")
+	}
+}
+
+func TestProjectConcurrentUpdates(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	root := t.TempDir()
+
+	// Without the process mutex, as separate `nunchux task record`
+	// processes would run them
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := saveLastRun(Run{Name: fmt.Sprintf("make:step%d", i), Dir: root}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if last := LastRuns(root); len(last) != 20 {
+		t.Errorf("kept %d of 20 last runs", len(last))
+	}
+}
@@ -0,0 +1,124 @@
+package history
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// Per-project state: the last run of each task, so the menu can show it
+// without reading the whole history, and the last task launched, to run it
+// again. One JSON file per project root, written when a run is launched or
+// recorded.
+
+// projectState is the state file of one project
+type projectState struct {
+	Root   string         `json:"root"`
+	Tasks  map[string]Run `json:"tasks"`            // Item name -> last run
+	Launch *Run           `json:"launch,omitempty"` // Most recently launched task
+}
+
+// ProjectRoot returns the root of the project dir is in: the nearest
+// directory with a .git entry, or dir itself
+func ProjectRoot(dir string) string {
+	for d := dir; ; {
+		if _, err := os.Stat(filepath.Join(d, ".git")); err == nil {
+			return d
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			return dir
+		}
+		d = parent
+	}
+}
+
+func projectPath(root string) string {
+	sum := sha1.Sum([]byte(root))
+	return filepath.Join(Dir(), "projects", hex.EncodeToString(sum[:8])+".json")
+}
+
+func readProject(root string) projectState {
+	state := projectState{Root: root, Tasks: make(map[string]Run)}
+	if data, err := os.ReadFile(projectPath(root)); err == nil {
+		json.Unmarshal(data, &state)
+	}
+	if state.Tasks == nil {
+		state.Tasks = make(map[string]Run)
+	}
+	return state
+}
+
+// LastRuns returns the last run of each task in the project dir is in,
+// keyed by item name
+func LastRuns(dir string) map[string]Run {
+	if dir == "" {
+		return nil
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	return readProject(ProjectRoot(dir)).Tasks
+}
+
+// LastLaunch returns the task most recently launched in the project dir is
+// in, as it was launched
+func LastLaunch(dir string) (Run, bool) {
+	if dir == "" {
+		return Run{}, false
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	state := readProject(ProjectRoot(dir))
+	if state.Launch == nil {
+		return Run{}, false
+	}
+	return *state.Launch, true
+}
+
+// SaveLaunch stores run, which has just been launched, as the last launch
+// in its project
+func SaveLaunch(run Run) error {
+	if run.Dir == "" {
+		return nil
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	return updateProject(ProjectRoot(run.Dir), func(state *projectState) {
+		state.Launch = &run
+	})
+}
+
+// saveLastRun stores run as the last run of its task in its project
+func saveLastRun(run Run) error {
+	if run.Dir == "" {
+		return nil
+	}
+	return updateProject(ProjectRoot(run.Dir), func(state *projectState) {
+		state.Tasks[run.Name] = run
+	})
+}
+
+// updateProject applies update to the state of a project. The state file
+// stays locked from the read to the write, so updates from other processes
+// (task records, launches) aren't lost.
+func updateProject(root string, update func(*projectState)) error {
+	path := projectPath(root)
+	unlock, err := lockPath(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	state := readProject(root)
+	update(&state)
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, func(f *os.File) error {
+		_, err := f.Write(data)
+		return err
+	})
+}
@@ -0,0 +1,168 @@
+package ignore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	m := New([]string{
+		"# comment",
+		"*.log",
+		"!keep.log",
+		"build/",
+		"/dist",
+		"docs/*.txt",
+		"**/tmp/**",
+		"a/**/z",
+		"file[0-9].go",
+	})
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"app.log", false, true},
+		{"sub/dir/app.log", false, true},
+		{"keep.log", false, false},
+		{"sub/keep.log", false, false},
+		{"build", true, true},
+		{"build", false, false}, // Directory-only pattern
+		{"src/build/out.o", false, true},
+		{"dist", true, true},
+		{"src/dist", true, false}, // Anchored to the root
+		{"docs/a.txt", false, true},
+		{"docs/sub/a.txt", false, false},
+		{"x/tmp/y", false, true},
+		{"a/z", false, true},
+		{"a/b/c/z", false, true},
+		{"file1.go", false, true},
+		{"fileX.go", false, false},
+		{"main.go", false, false},
+	}
+	for _, tt := range tests {
+		if got := m.Match(tt.path, tt.isDir); got != tt.want {
+			t.Errorf("Match(%q, %v) = %v, want %v", tt.path, tt.isDir, got, tt.want)
+		}
+	}
+}
+
+func TestMatchBase(t *testing.T) {
+	m := &Matcher{}
+	m.Add("pkg", []string{"/gen", "*.tmp"})
+
+	if !m.Match("pkg/gen/x.go", false) || !m.Match("pkg/a/b.tmp", false) {
+		t.Error("patterns should apply below their base")
+	}
+	if m.Match("gen/x.go", false) || m.Match("b.tmp", false) {
+		t.Error("patterns should not apply outside their base")
+	}
+}
+
+func TestSplitList(t *testing.T) {
+	got := SplitList(".git, node_modules,, *.log ")
+	if len(got) != 3 || got[0] != ".git" || got[1] != "node_modules" || got[2] != "*.log" {
+		t.Errorf("SplitList = %q", got)
+	}
+}
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestTree(t *testing.T) {
+	repo := t.TempDir()
+	global := filepath.Join(t.TempDir(), "ignore")
+	writeFiles(t, repo, map[string]string{
+		".git/info/exclude":   "secret.txt\n",
+		".gitignore":          "*.log\n/build/\n",
+		"src/.gitignore":      "gen/\n!keep.log\n",
+		"src/.ignore":         "*.tmp\n",
+		"src/app/.gitignore":  "local.go\n",
+		"other/.gitignore":    "*.go\n",
+		"src/app/keep.log":    "",
+		"src/app/main.go":     "",
+		"src/app/local.go":    "",
+		"src/gen/x.go":        "",
+		"src/scratch.tmp":     "",
+		"src/notes.swp":       "",
+		"build/out":           "",
+		"src/build/out":       "",
+		"secret.txt":          "",
+		"src/app/debug.log":   "",
+		"src/vendor/lib.go":   "",
+		"src/vendor/.ignore":  "!*.tmp\n",
+		"src/vendor/tmp.tmp":  "",
+		"src/app/sub/app.log": "",
+	})
+	writeFiles(t, filepath.Dir(global), map[string]string{"ignore": "*.swp\n"})
+	old := globalExcludesFile
+	globalExcludesFile = func() string { return global }
+	t.Cleanup(func() { globalExcludesFile = old })
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{".git", true, true},
+		{"secret.txt", false, true},    // .git/info/exclude
+		{"src/notes.swp", false, true}, // Global excludes file
+		{"build", true, true},
+		{"src/build", true, false}, // Anchored to its .gitignore
+		{"src", true, false},
+		{"src/gen", true, true},
+		{"src/app", true, false},
+		{"src/app/debug.log", false, true},
+		{"src/app/keep.log", false, false}, // Negated in a nested .gitignore
+		{"src/app/sub", true, false},
+		{"src/app/sub/app.log", false, true},
+		{"src/app/main.go", false, false},
+		{"src/app/local.go", false, true},
+		{"src/scratch.tmp", false, true}, // .ignore
+		{"src/vendor", true, false},
+		{"src/vendor/tmp.tmp", false, false}, // Re-included by a deeper .ignore
+		{"src/vendor/lib.go", false, false},  // other/.gitignore does not apply
+	}
+	tree := LoadTree(repo)
+	for _, tt := range tests {
+		if got := tree.Match(tt.path, tt.isDir); got != tt.want {
+			t.Errorf("Match(%q, %v) = %v, want %v", tt.path, tt.isDir, got, tt.want)
+		}
+	}
+
+	// Rooted below the repository, the parents' ignore files still apply
+	sub := LoadTree(filepath.Join(repo, "src", "app"))
+	for path, want := range map[string]bool{"debug.log": true, "keep.log": false, "local.go": true, "main.go": false} {
+		if got := sub.Match(path, false); got != want {
+			t.Errorf("below the repository root: Match(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestTreeOutsideRepo(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{".gitignore": "*.log\n", ".ignore": "*.tmp\n"})
+	old := globalExcludesFile
+	globalExcludesFile = func() string { t.Error("global excludes file read outside a repository"); return "" }
+	t.Cleanup(func() { globalExcludesFile = old })
+
+	tree := LoadTree(root)
+	if tree.Match("a.log", false) {
+		t.Error(".gitignore should only apply inside a repository")
+	}
+	if !tree.Match("a.tmp", false) {
+		t.Error(".ignore should apply outside a repository")
+	}
+}
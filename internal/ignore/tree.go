@@ -0,0 +1,146 @@
+package ignore
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Ignore files read in every directory, later ones taking precedence
+var dirIgnoreFiles = []string{".gitignore", ".ignore"}
+
+// globalExcludesFile returns git's global excludes file: core.excludesFile,
+// or the XDG default
+var globalExcludesFile = func() string {
+	if out, err := exec.Command("git", "config", "--path", "--get", "core.excludesFile").Output(); err == nil {
+		if path := strings.TrimSpace(string(out)); path != "" {
+			return path
+		}
+	}
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		return filepath.Join(xdg, "git", "ignore")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "git", "ignore")
+}
+
+// Tree matches paths below a root the way git, rg and fd do: against the
+// global excludes file, .git/info/exclude, and the .gitignore and .ignore
+// files of every directory from the repository root down. The git files
+// only apply inside a repository. Ignore files of directories below the
+// root are read the first time a path inside them is matched, so parents
+// must be matched before their children, as a walk does.
+type Tree struct {
+	mu      sync.Mutex
+	top     string // Repository root, or the root outside a repository
+	inRepo  bool   // Root is inside a git repository
+	prefix  string // Root relative to top ("" = the same)
+	m       Matcher
+	visited map[string]bool // Directories (relative to root) whose files are read
+}
+
+// LoadTree returns a matcher for the ignore files that apply below root
+func LoadTree(root string) *Tree {
+	root, _ = filepath.Abs(root)
+	t := &Tree{top: root, visited: map[string]bool{"": true}}
+
+	repo := findRepo(root)
+	if repo != "" {
+		t.top, t.inRepo = repo, true
+		if rel, err := filepath.Rel(repo, root); err == nil && rel != "." {
+			t.prefix = filepath.ToSlash(rel)
+		}
+		if global := globalExcludesFile(); global != "" {
+			t.m.AddFile("", global)
+		}
+		t.m.AddFile("", filepath.Join(repo, ".git", "info", "exclude"))
+	}
+
+	// Ignore files from the top down to the root itself
+	dir := ""
+	t.addDir(dir)
+	if t.prefix != "" {
+		for _, part := range strings.Split(t.prefix, "/") {
+			dir = join(dir, part)
+			t.addDir(dir)
+		}
+	}
+	return t
+}
+
+// findRepo returns the closest directory at or above dir containing .git,
+// or "" if there is none
+func findRepo(dir string) string {
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// addDir adds the ignore files of dir, relative to top
+func (t *Tree) addDir(dir string) {
+	for _, name := range dirIgnoreFiles {
+		if name == ".gitignore" && !t.inRepo {
+			continue
+		}
+		t.m.AddFile(dir, filepath.Join(t.top, filepath.FromSlash(dir), name))
+	}
+}
+
+// Match reports whether rel, a slash-separated path relative to the root,
+// is ignored. The .git directory always is.
+func (t *Tree) Match(rel string, isDir bool) bool {
+	if t == nil {
+		return false
+	}
+	rel = strings.Trim(filepath.ToSlash(rel), "/")
+	if rel == "" {
+		return false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	parts := strings.Split(rel, "/")
+	dir := ""
+	for i, part := range parts {
+		path := join(dir, part)
+		last := i == len(parts)-1
+		if part == ".git" && (isDir || !last) {
+			return true
+		}
+		if t.m.matchOne(join(t.prefix, path), isDir || !last) {
+			return true
+		}
+		if last {
+			break
+		}
+		dir = path
+		if !t.visited[dir] {
+			t.visited[dir] = true
+			t.addDir(join(t.prefix, dir))
+		}
+	}
+	return false
+}
+
+// join joins two slash-separated relative paths, either of which may be ""
+func join(a, b string) string {
+	if a == "" {
+		return b
+	}
+	if b == "" {
+		return a
+	}
+	return a + "/" + b
+}
@@ -3,10 +3,7 @@ package items
 import (
 	"context"
 	"fmt"
-	"os"
-	"os/exec"
 	"strings"
-	"time"
 
 	"nunchux/internal/config"
 )
@@ -15,6 +12,7 @@ import (
 type AppItem struct {
 	App      config.App
 	Settings *config.Settings
+	Runner   StatusRunner // Runs the status command (nil = one process per call)
 }
 
 // Ensure AppItem implements Item
@@ -45,13 +43,25 @@ func (a *AppItem) DisplayName() string {
 }
 
 func (a *AppItem) FormatLine(ctx context.Context, isRunning bool) string {
+	return a.formatLine(isRunning, a.getStatus(ctx))
+}
+
+// PendingLine formats the item without running its status command
+func (a *AppItem) PendingLine(isRunning bool) string {
+	if a.App.Status == "" && a.App.StatusScript == "" {
+		return a.formatLine(isRunning, "")
+	}
+	return a.formatLine(isRunning, StatusPending)
+}
+
+func (a *AppItem) formatLine(isRunning bool, status string) string {
 	icon := a.Settings.IconStopped
 	if isRunning {
 		icon = a.Settings.IconRunning
 	}
 
 	desc := a.App.Desc
-	if status := a.getStatus(ctx); status != "" {
+	if status != "" {
 		if desc != "" {
 			desc = desc + " " + status
 		} else {
@@ -69,6 +79,11 @@ func (a *AppItem) FormatLine(ctx context.Context, isRunning bool) string {
 	)
 }
 
+// StatusText runs the status command and returns its output
+func (a *AppItem) StatusText(ctx context.Context) string {
+	return a.getStatus(ctx)
+}
+
 func (a *AppItem) getStatus(ctx context.Context) string {
 	statusCmd := a.App.Status
 	if a.App.StatusScript != "" {
@@ -78,22 +93,24 @@ func (a *AppItem) getStatus(ctx context.Context) string {
 		return ""
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
-	defer cancel()
-
-	cmd := exec.CommandContext(ctx, "bash", "-c", statusCmd)
+	// Menu budget already spent - don't start another command
+	if ctx.Err() != nil {
+		return StatusPending
+	}
 
-	// Add bin directory to PATH for helper scripts (lines, ago, nearest)
-	if a.Settings.BinDir != "" {
-		cmd.Env = append(os.Environ(), "PATH="+a.Settings.BinDir+":"+os.Getenv("PATH"))
+	runner := a.Runner
+	if runner == nil {
+		runner = &ProcessStatusRunner{BinDir: a.Settings.BinDir}
 	}
 
-	output, err := cmd.Output()
+	output, err := runner.Run(ctx, statusCmd)
 	if err != nil {
+		if ctx.Err() != nil {
+			return StatusPending
+		}
 		return ""
 	}
-
-	return strings.TrimSpace(string(output))
+	return output
 }
 
 // App-specific accessors with defaults from Settings
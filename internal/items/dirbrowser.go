@@ -4,19 +4,25 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"nunchux/internal/config"
+	"nunchux/internal/ignore"
 )
 
 // DirbrowserItem represents a directory browser menu item
 type DirbrowserItem struct {
 	Dirbrowser config.Dirbrowser
 	Settings   *config.Settings
+	CacheCount bool // Remember the file count for cache_ttl (long-running processes)
+
+	countMu    sync.Mutex
+	count      int
+	countAt    time.Time
+	refreshing bool
 }
 
 // Ensure DirbrowserItem implements Item
@@ -43,16 +49,26 @@ func (d *DirbrowserItem) DisplayName() string {
 }
 
 func (d *DirbrowserItem) FormatLine(ctx context.Context, isRunning bool) string {
-	icon := "▸"
-
 	// Get file count (with timeout)
-	fileCount := d.getFileCount(ctx)
+	fileCount := d.fileCount(ctx)
 	countStr := fmt.Sprintf("(%d files)", fileCount)
-	if fileCount > 1000 {
-		countStr = "(1000+ files)"
+	if ctx.Err() != nil {
+		countStr = StatusPending // Menu budget ran out before counting finished
+	} else if fileCount > maxFileCount {
+		countStr = fmt.Sprintf("(%d+ files)", maxFileCount)
 	} else if fileCount == 1 {
 		countStr = "(1 file)"
 	}
+	return d.formatLine(countStr)
+}
+
+// PendingLine formats the item without counting its files
+func (d *DirbrowserItem) PendingLine(isRunning bool) string {
+	return d.formatLine(StatusPending)
+}
+
+func (d *DirbrowserItem) formatLine(countStr string) string {
+	icon := "▸"
 
 	// Use \x00 as separator between name and desc for reliable parsing
 	display := fmt.Sprintf("%s %s\x00%s", icon, d.Dirbrowser.Name, countStr)
@@ -94,23 +110,55 @@ func (d *DirbrowserItem) GetSecondaryAction() config.Action {
 	return d.Settings.SecondaryAction
 }
 
-// getFileCount returns the number of files in the directory
+// fileCount returns the file count, from the cache if CacheCount is set.
+// A stale count is returned as-is while a background refresh runs.
+func (d *DirbrowserItem) fileCount(ctx context.Context) int {
+	if !d.CacheCount || d.Dirbrowser.CacheTTL <= 0 {
+		return d.getFileCount(ctx)
+	}
+
+	d.countMu.Lock()
+	if !d.countAt.IsZero() {
+		count := d.count
+		if time.Since(d.countAt) > time.Duration(d.Dirbrowser.CacheTTL)*time.Second && !d.refreshing {
+			d.refreshing = true
+			go func() {
+				n := d.getFileCount(context.Background())
+				d.countMu.Lock()
+				d.count, d.countAt, d.refreshing = n, time.Now(), false
+				d.countMu.Unlock()
+			}()
+		}
+		d.countMu.Unlock()
+		return count
+	}
+	d.countMu.Unlock()
+
+	n := d.getFileCount(ctx)
+	if ctx.Err() == nil {
+		d.countMu.Lock()
+		d.count, d.countAt = n, time.Now()
+		d.countMu.Unlock()
+	}
+	return n
+}
+
+// maxFileCount is where the menu stops counting and shows "1000+ files"
+const maxFileCount = 1000
+
+// getFileCount returns the number of files in the directory, counting at
+// most one past maxFileCount
 func (d *DirbrowserItem) getFileCount(ctx context.Context) int {
 	ctx, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
 	defer cancel()
 
-	args := d.buildFindArgs()
-	cmd := exec.CommandContext(ctx, "find", args...)
-	output, err := cmd.Output()
-	if err != nil {
+	count := 0
+	opts := d.walkOptions()
+	opts.Limit = maxFileCount + 1
+	if err := walkFiles(ctx, d.expandPath(d.Dirbrowser.Directory), opts, func(walkFile) { count++ }); err != nil {
 		return 0
 	}
-
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-	if len(lines) == 1 && lines[0] == "" {
-		return 0
-	}
-	return len(lines)
+	return count
 }
 
 // expandPath expands ~ to home directory
@@ -122,40 +170,25 @@ func (d *DirbrowserItem) expandPath(path string) string {
 	return path
 }
 
-// buildFindArgs builds arguments for the find command
-func (d *DirbrowserItem) buildFindArgs() []string {
-	dir := d.expandPath(d.Dirbrowser.Directory)
+// walkOptions returns the options of walks over the directory
+func (d *DirbrowserItem) walkOptions() walkOptions {
 	depth := d.Dirbrowser.Depth
 	if depth == 0 {
 		depth = 1 // Default depth
 	}
-
-	args := []string{dir, "-maxdepth", strconv.Itoa(depth), "-type", "f"}
-
-	// Add exclusion patterns
-	if d.Settings.ExcludePatterns != "" {
-		patterns := strings.Split(d.Settings.ExcludePatterns, ",")
-		for _, pattern := range patterns {
-			pattern = strings.TrimSpace(pattern)
-			if pattern == "" {
-				continue
-			}
-			if strings.HasPrefix(pattern, "*") {
-				// Glob pattern - match filename only
-				args = append(args, "!", "-name", pattern)
-			} else {
-				// Directory/file name
-				args = append(args, "!", "-path", "*/"+pattern+"/*", "!", "-name", pattern)
-			}
-		}
+	// The dirbrowser's own patterns come last, so "!" ones override
+	exclude := ignore.New(ignore.SplitList(d.Settings.ExcludePatterns))
+	exclude.Add("", d.Dirbrowser.Exclude)
+	opts := walkOptions{
+		Depth:          depth,
+		Glob:           d.Dirbrowser.Glob,
+		Exclude:        exclude,
+		FollowSymlinks: d.Dirbrowser.FollowSymlinks,
 	}
-
-	// Add glob filter
-	if d.Dirbrowser.Glob != "" {
-		args = append(args, "-name", d.Dirbrowser.Glob)
+	if d.Dirbrowser.RespectGitignore {
+		opts.Gitignore = ignore.LoadTree(d.expandPath(d.Dirbrowser.Directory))
 	}
-
-	return args
+	return opts
 }
 
 // FileEntry represents a file in the dirbrowser listing
@@ -169,57 +202,20 @@ type FileEntry struct {
 
 // ListFiles returns files in the directory with metadata
 func (d *DirbrowserItem) ListFiles(ctx context.Context) ([]FileEntry, error) {
-	dir := d.expandPath(d.Dirbrowser.Directory)
-	args := d.buildFindArgs()
-
-	// Add printf to get mtime
-	args = append(args, "-printf", "%T@\t%p\n")
-
-	cmd := exec.CommandContext(ctx, "find", args...)
-	output, err := cmd.Output()
-	if err != nil {
-		return nil, err
-	}
-
 	var entries []FileEntry
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-
-	for _, line := range lines {
-		if line == "" {
-			continue
-		}
-
-		parts := strings.SplitN(line, "\t", 2)
-		if len(parts) != 2 {
-			continue
-		}
-
-		mtimeFloat, _ := strconv.ParseFloat(parts[0], 64)
-		modTime := time.Unix(int64(mtimeFloat), 0)
-		filePath := parts[1]
-
-		// Get relative path
-		relPath := filePath
-		if strings.HasPrefix(filePath, dir+"/") {
-			relPath = filePath[len(dir)+1:]
-		}
-
-		// Get folder (first component of relative path)
-		folder := relPath
-		filename := filepath.Base(filePath)
-		if idx := strings.Index(relPath, "/"); idx != -1 {
-			folder = relPath[:idx]
-		} else {
-			folder = filename
-		}
-
+	err := walkFiles(ctx, d.expandPath(d.Dirbrowser.Directory), d.walkOptions(), func(f walkFile) {
+		// Folder is the first component of the relative path
+		folder, _, _ := strings.Cut(f.RelPath, "/")
 		entries = append(entries, FileEntry{
-			Path:     filePath,
-			RelPath:  relPath,
+			Path:     f.Path,
+			RelPath:  f.RelPath,
 			Folder:   folder,
-			Filename: filename,
-			ModTime:  modTime,
+			Filename: filepath.Base(f.Path),
+			ModTime:  f.Info.ModTime(),
 		})
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Sort entries based on sort mode
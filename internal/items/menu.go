@@ -3,10 +3,6 @@ package items
 import (
 	"context"
 	"fmt"
-	"os"
-	"os/exec"
-	"strings"
-	"time"
 
 	"nunchux/internal/config"
 )
@@ -15,6 +11,7 @@ import (
 type MenuItem struct {
 	Menu     config.Menu
 	Settings *config.Settings
+	Runner   StatusRunner // Runs the status command (nil = one process per call)
 }
 
 // Ensure MenuItem implements Item
@@ -41,10 +38,22 @@ func (m *MenuItem) DisplayName() string {
 }
 
 func (m *MenuItem) FormatLine(ctx context.Context, isRunning bool) string {
+	return m.formatLine(m.getStatus(ctx))
+}
+
+// PendingLine formats the item without running its status command
+func (m *MenuItem) PendingLine(isRunning bool) string {
+	if m.Menu.Status == "" {
+		return m.formatLine("")
+	}
+	return m.formatLine(StatusPending)
+}
+
+func (m *MenuItem) formatLine(status string) string {
 	icon := "▸"
 
 	desc := m.Menu.Desc
-	if status := m.getStatus(ctx); status != "" {
+	if status != "" {
 		if desc != "" {
 			desc = desc + " " + status
 		} else {
@@ -72,25 +81,32 @@ func (m *MenuItem) GetSecondaryAction() config.Action {
 	return ""
 }
 
+// StatusText runs the status command and returns its output
+func (m *MenuItem) StatusText(ctx context.Context) string {
+	return m.getStatus(ctx)
+}
+
 func (m *MenuItem) getStatus(ctx context.Context) string {
 	if m.Menu.Status == "" {
 		return ""
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
-	defer cancel()
-
-	cmd := exec.CommandContext(ctx, "bash", "-c", m.Menu.Status)
+	// Menu budget already spent - don't start another command
+	if ctx.Err() != nil {
+		return StatusPending
+	}
 
-	// Add bin directory to PATH for helper scripts (lines, ago, nearest)
-	if m.Settings.BinDir != "" {
-		cmd.Env = append(os.Environ(), "PATH="+m.Settings.BinDir+":"+os.Getenv("PATH"))
+	runner := m.Runner
+	if runner == nil {
+		runner = &ProcessStatusRunner{BinDir: m.Settings.BinDir}
 	}
 
-	output, err := cmd.Output()
+	output, err := runner.Run(ctx, m.Menu.Status)
 	if err != nil {
+		if ctx.Err() != nil {
+			return StatusPending
+		}
 		return ""
 	}
-
-	return strings.TrimSpace(string(output))
+	return output
 }
@@ -0,0 +1,136 @@
+package items
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"nunchux/internal/config"
+	"nunchux/internal/history"
+	"nunchux/internal/shell"
+)
+
+// pipelineLabelWidth is the longest label of a cmd: step
+const pipelineLabelWidth = 16
+
+// PipelineStep is a resolved pipeline step
+type PipelineStep struct {
+	Label string // Shown in the window name, e.g. "fmt"
+	Cmd   string
+}
+
+// PipelineItem is a pipeline in the menu. It is listed with the
+// taskrunner tasks, since its steps are resolved against them.
+type PipelineItem struct {
+	Pipeline config.Pipeline
+	Steps    []PipelineStep
+	Settings *config.Settings
+	LastRun  *history.Run // Last run in the current project (nil = never run)
+}
+
+// Ensure PipelineItem implements Item
+var _ Item = (*PipelineItem)(nil)
+
+func (p *PipelineItem) Name() string {
+	return "pipeline:" + p.Pipeline.Name
+}
+
+func (p *PipelineItem) Type() ItemType {
+	return TypeTaskrunner
+}
+
+func (p *PipelineItem) Shortcut() string {
+	return ""
+}
+
+func (p *PipelineItem) Parent() string {
+	return ""
+}
+
+func (p *PipelineItem) DisplayName() string {
+	return "pipeline " + p.Pipeline.Name
+}
+
+func (p *PipelineItem) FormatLine(ctx context.Context, isRunning bool) string {
+	desc := p.Pipeline.Desc
+	if desc == "" {
+		labels := make([]string, len(p.Steps))
+		for i, step := range p.Steps {
+			labels[i] = step.Label
+		}
+		desc = strings.Join(labels, " → ")
+	}
+	return formatTaskLine(p.Settings, "", p.DisplayName(), desc, "", p.Name(), "", p.LastRun, isRunning, false)
+}
+
+// GetPrimaryAction returns the action for this pipeline
+func (p *PipelineItem) GetPrimaryAction() config.Action {
+	if p.Pipeline.PrimaryAction != "" {
+		return p.Pipeline.PrimaryAction
+	}
+	return config.ActionWindow
+}
+
+// GetSecondaryAction returns the secondary action for this pipeline
+func (p *PipelineItem) GetSecondaryAction() config.Action {
+	if p.Pipeline.SecondaryAction != "" {
+		return p.Pipeline.SecondaryAction
+	}
+	return config.ActionBackgroundWindow
+}
+
+// WindowName returns the window name for tmux
+func (p *PipelineItem) WindowName() string {
+	return "pipeline » " + p.Pipeline.Name
+}
+
+// Parallel reports whether the steps run at the same time
+func (p *PipelineItem) Parallel() bool {
+	return p.Pipeline.Mode == config.PipelineParallel
+}
+
+// resolvePipeline resolves the steps of a pipeline against the loaded
+// tasks. Tasks run with the arguments last used for them in dir. It fails
+// if a step names a task that is not available.
+func (r *Registry) resolvePipeline(p config.Pipeline, dir string) ([]PipelineStep, error) {
+	if len(p.Steps) == 0 {
+		return nil, fmt.Errorf("pipeline %s has no steps", p.Name)
+	}
+
+	var steps []PipelineStep
+	for _, step := range p.Steps {
+		if cmd, ok := strings.CutPrefix(step, "cmd:"); ok {
+			cmd = strings.TrimSpace(cmd)
+			label := cmd
+			if runes := []rune(cmd); len(runes) > pipelineLabelWidth {
+				label = string(runes[:pipelineLabelWidth-1]) + "…"
+			}
+			steps = append(steps, PipelineStep{Label: label, Cmd: cmd})
+			continue
+		}
+
+		tr := r.FindTaskrunnerItem(step)
+		if tr == nil {
+			return nil, fmt.Errorf("pipeline %s: task not found: %s", p.Name, step)
+		}
+		cmd, err := tr.Task.Command(LoadTaskArgs(dir, tr.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("pipeline %s: %s: %w", p.Name, step, err)
+		}
+		if tr.Task.Dir != "" {
+			cmd = "cd " + shell.Quote(tr.Task.Dir) + " && " + cmd
+		}
+		steps = append(steps, PipelineStep{Label: tr.Task.FullName(), Cmd: cmd})
+	}
+	return steps, nil
+}
+
+// FindPipeline finds a pipeline item by name (format: pipeline:name)
+func (r *Registry) FindPipeline(name string) *PipelineItem {
+	for _, item := range r.TaskrunnerItems {
+		if p, ok := item.(*PipelineItem); ok && p.Name() == name {
+			return p
+		}
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package items
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"nunchux/internal/config"
+)
+
+// TaskProvider discovers the tasks of one taskrunner
+type TaskProvider interface {
+	Name() string
+	Icon() string  // Default divider icon
+	Label() string // Default divider label
+	// Tasks lists the tasks available from dir. A provider whose tool or
+	// project file is missing returns no tasks and no error.
+	Tasks(ctx context.Context, dir string) ([]TaskrunnerTask, error)
+}
+
+// builtinProviders create the providers implemented in Go, by name
+var builtinProviders = map[string]func(cfg config.TaskrunnerConfig) TaskProvider{
+	"just": func(config.TaskrunnerConfig) TaskProvider { return &JustProvider{} },
+	"npm": func(cfg config.TaskrunnerConfig) TaskProvider {
+		return &NpmProvider{Args: cfg.Options["args"] == "true"}
+	},
+	"task": func(config.TaskrunnerConfig) TaskProvider { return &TaskfileProvider{} },
+	"make": func(cfg config.TaskrunnerConfig) TaskProvider {
+		return &MakeProvider{Includes: cfg.Options["include_makefiles"] == "true"}
+	},
+	"cargo":   func(config.TaskrunnerConfig) TaskProvider { return &CargoProvider{} },
+	"go":      func(config.TaskrunnerConfig) TaskProvider { return &GoProvider{} },
+	"python":  func(config.TaskrunnerConfig) TaskProvider { return &PythonProvider{} },
+	"compose": func(config.TaskrunnerConfig) TaskProvider { return &ComposeProvider{} },
+}
+
+// FindTaskProvider returns the provider for a taskrunner: an inline
+// provider (list_cmd), the user's own provider script, a built-in provider,
+// or else a shipped provider script. It returns nil if there is none.
+func FindTaskProvider(cfg config.TaskrunnerConfig, binDir string) TaskProvider {
+	if listCmd := cfg.Options["list_cmd"]; listCmd != "" {
+		return &InlineProvider{name: cfg.Name, listCmd: listCmd, runTemplate: cfg.Options["run_template"], binDir: binDir}
+	}
+	// A script in the config directory replaces a built-in provider
+	if scriptPath := userProviderScript(cfg.Name); scriptPath != "" {
+		return &ScriptProvider{name: cfg.Name, path: scriptPath}
+	}
+	if newProvider, ok := builtinProviders[cfg.Name]; ok {
+		return newProvider(cfg)
+	}
+	if scriptPath := findProviderScript(cfg.Name, binDir); scriptPath != "" {
+		return &ScriptProvider{name: cfg.Name, path: scriptPath}
+	}
+	return nil
+}
+
+// ScriptProvider runs a bash provider script implementing plugin_icon,
+// plugin_label and plugin_items
+type ScriptProvider struct {
+	name string
+	path string
+
+	once  sync.Once
+	icon  string
+	label string
+}
+
+func (s *ScriptProvider) Name() string {
+	return s.name
+}
+
+func (s *ScriptProvider) Icon() string {
+	s.loadInfo()
+	return s.icon
+}
+
+func (s *ScriptProvider) Label() string {
+	s.loadInfo()
+	return s.label
+}
+
+// loadInfo reads the icon and label with a single bash invocation
+func (s *ScriptProvider) loadInfo() {
+	s.once.Do(func() {
+		output := getProviderValue(context.Background(), s.path, `printf '%s\x1f%s' "$(plugin_icon)" "$(plugin_label)"`)
+		icon, label, _ := strings.Cut(output, "\x1f")
+		s.icon = strings.TrimSpace(icon)
+		s.label = strings.TrimSpace(label)
+	})
+}
+
+func (s *ScriptProvider) Tasks(ctx context.Context, dir string) ([]TaskrunnerTask, error) {
+	return getProviderTasks(ctx, s.path, dir)
+}
+
+// InlineProvider lists tasks with a shell command from the config
+// (list_cmd), run in the project directory. Its output is the same as a
+// provider script's plugin_items; run_template builds the command of tasks
+// that don't have one.
+type InlineProvider struct {
+	name        string
+	listCmd     string
+	runTemplate string
+	binDir      string
+}
+
+func (p *InlineProvider) Name() string  { return p.name }
+func (p *InlineProvider) Icon() string  { return "" }
+func (p *InlineProvider) Label() string { return p.name }
+
+func (p *InlineProvider) Tasks(ctx context.Context, dir string) ([]TaskrunnerTask, error) {
+	ctx, cancel := context.WithTimeout(ctx, providerTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "bash", "-c", p.listCmd)
+	cmd.Dir = dir
+	cmd.Env = statusEnv(p.binDir)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s list_cmd: %w", p.name, err)
+	}
+	// JSON lines are protocol v2, as from plugin_items_json
+	if strings.HasPrefix(strings.TrimSpace(string(output)), "{") {
+		return parseProviderItems(string(output), dir, p.runTemplate), nil
+	}
+	return parseProviderTasks(string(output), p.runTemplate), nil
+}
+
+// providerTimeout bounds the external commands providers run to list tasks
+const providerTimeout = 2 * time.Second
+
+// providerOutput runs a command in dir and returns its stdout
+func providerOutput(ctx context.Context, dir, name string, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, providerTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return output, nil
+}
+
+// findUpward returns the path of the first of names found in dir or its
+// closest ancestor, or "" if there is none
+func findUpward(dir string, names ...string) string {
+	for d := dir; ; d = filepath.Dir(d) {
+		for _, name := range names {
+			path := filepath.Join(d, name)
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				return path
+			}
+		}
+		if parent := filepath.Dir(d); parent == d {
+			return ""
+		}
+	}
+}
+
+// hasCommand reports whether a command is on PATH
+func hasCommand(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
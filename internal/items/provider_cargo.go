@@ -0,0 +1,209 @@
+package items
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"nunchux/internal/shell"
+)
+
+// CargoProvider offers build, test and run tasks for the nearest Cargo
+// package or workspace
+type CargoProvider struct{}
+
+func (p *CargoProvider) Name() string  { return "cargo" }
+func (p *CargoProvider) Icon() string  { return "🦀" }
+func (p *CargoProvider) Label() string { return "cargo" }
+
+// cargoPackage is a package manifest with the binaries it builds
+type cargoPackage struct {
+	name string
+	bins []string
+}
+
+func (p *CargoProvider) Tasks(ctx context.Context, dir string) ([]TaskrunnerTask, error) {
+	if !hasCommand("cargo") {
+		return nil, nil
+	}
+	manifest := findUpward(dir, "Cargo.toml")
+	if manifest == "" {
+		return nil, nil
+	}
+	root := cargoWorkspaceRoot(manifest)
+	rootDir := filepath.Dir(root)
+
+	data, err := os.ReadFile(root)
+	if err != nil {
+		return nil, err
+	}
+	doc := parseTOML(string(data))
+
+	var packages []cargoPackage
+	if pkg := readCargoPackage(rootDir, doc); pkg.name != "" {
+		packages = append(packages, pkg)
+	}
+	workspace := tomlFind(doc, "workspace")
+	if workspace != nil {
+		for _, memberDir := range cargoMembers(rootDir, workspace) {
+			if memberDir == rootDir {
+				continue
+			}
+			memberData, err := os.ReadFile(filepath.Join(memberDir, "Cargo.toml"))
+			if err != nil {
+				continue
+			}
+			if pkg := readCargoPackage(memberDir, parseTOML(string(memberData))); pkg.name != "" {
+				packages = append(packages, pkg)
+			}
+		}
+	}
+
+	tasks := []TaskrunnerTask{
+		{TaskName: "build", Cmd: "cargo build", Description: "Build all packages"},
+		{TaskName: "test", Cmd: "cargo test", Description: "Run all tests"},
+	}
+	if workspace != nil {
+		tasks[0].Cmd = "cargo build --workspace"
+		tasks[1].Cmd = "cargo test --workspace"
+	}
+
+	for _, pkg := range packages {
+		for _, bin := range pkg.bins {
+			args := "run --bin " + shell.Quote(bin)
+			if workspace != nil {
+				args = "run -p " + shell.Quote(pkg.name) + " --bin " + shell.Quote(bin)
+			}
+			tasks = append(tasks, TaskrunnerTask{TaskName: bin, Group: "run", Cmd: "cargo " + args})
+		}
+	}
+
+	// Per-member build and test in a workspace
+	if workspace != nil {
+		for _, pkg := range packages {
+			pkgArg := "-p " + shell.Quote(pkg.name)
+			tasks = append(tasks,
+				TaskrunnerTask{TaskName: "build", Group: pkg.name, Cmd: "cargo build " + pkgArg},
+				TaskrunnerTask{TaskName: "test", Group: pkg.name, Cmd: "cargo test " + pkgArg},
+			)
+		}
+	}
+
+	// Everything runs from the workspace root
+	for i := range tasks {
+		tasks[i].Dir = rootDir
+	}
+	return tasks, nil
+}
+
+// SourceFiles returns the workspace manifest and members, and the files
+// binaries are discovered from
+func (p *CargoProvider) SourceFiles(dir string) []string {
+	manifest := findUpward(dir, "Cargo.toml")
+	if manifest == "" {
+		return nil
+	}
+	root := cargoWorkspaceRoot(manifest)
+	rootDir := filepath.Dir(root)
+	packageDirs := []string{rootDir}
+	files := []string{root}
+	if data, err := os.ReadFile(root); err == nil {
+		if workspace := tomlFind(parseTOML(string(data)), "workspace"); workspace != nil {
+			for _, memberDir := range cargoMembers(rootDir, workspace) {
+				packageDirs = append(packageDirs, memberDir)
+				files = append(files, filepath.Join(memberDir, "Cargo.toml"), filepath.Dir(memberDir))
+			}
+		}
+	}
+	for _, d := range packageDirs {
+		files = append(files, filepath.Join(d, "src"), filepath.Join(d, "src", "bin"))
+	}
+	return files
+}
+
+// cargoWorkspaceRoot returns the manifest of the workspace containing
+// manifest, or manifest itself
+func cargoWorkspaceRoot(manifest string) string {
+	for d := filepath.Dir(filepath.Dir(manifest)); ; d = filepath.Dir(d) {
+		candidate := filepath.Join(d, "Cargo.toml")
+		if data, err := os.ReadFile(candidate); err == nil {
+			if tomlFind(parseTOML(string(data)), "workspace") != nil {
+				return candidate
+			}
+		}
+		if parent := filepath.Dir(d); parent == d {
+			return manifest
+		}
+	}
+}
+
+// cargoMembers expands the workspace's member globs into package dirs
+func cargoMembers(rootDir string, workspace *tomlTable) []string {
+	excluded := make(map[string]bool)
+	for _, pattern := range tomlStrings(workspace.Values["exclude"]) {
+		matches, _ := filepath.Glob(filepath.Join(rootDir, pattern))
+		for _, match := range matches {
+			excluded[match] = true
+		}
+	}
+
+	var members []string
+	for _, pattern := range tomlStrings(workspace.Values["members"]) {
+		matches, _ := filepath.Glob(filepath.Join(rootDir, pattern))
+		for _, match := range matches {
+			if excluded[match] {
+				continue
+			}
+			if _, err := os.Stat(filepath.Join(match, "Cargo.toml")); err == nil {
+				members = append(members, match)
+			}
+		}
+	}
+	return members
+}
+
+// readCargoPackage reads the package name and binary targets of a manifest,
+// including Cargo's auto-discovered binaries
+func readCargoPackage(dir string, doc []tomlTable) cargoPackage {
+	pkg := cargoPackage{name: tomlFind(doc, "package").String("name")}
+	if pkg.name == "" {
+		return pkg
+	}
+
+	seen := make(map[string]bool)
+	add := func(bin string) {
+		if bin != "" && !seen[bin] {
+			seen[bin] = true
+			pkg.bins = append(pkg.bins, bin)
+		}
+	}
+
+	for _, bin := range tomlFindAll(doc, "bin") {
+		add(bin.String("name"))
+	}
+
+	if tomlFind(doc, "package").String("autobins") != "false" {
+		if _, err := os.Stat(filepath.Join(dir, "src", "main.rs")); err == nil {
+			add(pkg.name)
+		}
+		entries, _ := os.ReadDir(filepath.Join(dir, "src", "bin"))
+		var auto []string
+		for _, entry := range entries {
+			name := entry.Name()
+			if entry.IsDir() {
+				if _, err := os.Stat(filepath.Join(dir, "src", "bin", name, "main.rs")); err == nil {
+					auto = append(auto, name)
+				}
+			} else if strings.HasSuffix(name, ".rs") {
+				auto = append(auto, strings.TrimSuffix(name, ".rs"))
+			}
+		}
+		sort.Strings(auto)
+		for _, bin := range auto {
+			add(bin)
+		}
+	}
+	return pkg
+}
@@ -0,0 +1,61 @@
+package items
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"nunchux/internal/shell"
+)
+
+// ComposeProvider offers up, logs, restart and exec tasks for each service
+// of the nearest compose file. The file is read directly, so listing works
+// without docker running.
+type ComposeProvider struct{}
+
+func (p *ComposeProvider) Name() string  { return "compose" }
+func (p *ComposeProvider) Icon() string  { return "🐳" }
+func (p *ComposeProvider) Label() string { return "compose" }
+
+// composeFileNames in the order docker compose looks for them
+var composeFileNames = []string{"compose.yaml", "compose.yml", "docker-compose.yaml", "docker-compose.yml"}
+
+// composeActions are the tasks offered per service: label and arguments,
+// with %s for the service name
+var composeActions = []struct {
+	label string
+	args  string
+}{
+	{"up", "up %s"},
+	{"logs -f", "logs -f %s"},
+	{"restart", "restart %s"},
+	{"exec sh", "exec %s sh"},
+}
+
+func (p *ComposeProvider) Tasks(ctx context.Context, dir string) ([]TaskrunnerTask, error) {
+	path := findUpward(dir, composeFileNames...)
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	composeDir := filepath.Dir(path)
+	var tasks []TaskrunnerTask
+	for _, service := range yamlMapping(string(data), "services") {
+		desc := service.Scalar("image")
+		for _, action := range composeActions {
+			tasks = append(tasks, TaskrunnerTask{
+				TaskName:    action.label,
+				Group:       service.Key,
+				Cmd:         "docker compose " + fmt.Sprintf(action.args, shell.Quote(service.Key)),
+				Dir:         composeDir,
+				Description: desc,
+			})
+		}
+	}
+	return tasks, nil
+}
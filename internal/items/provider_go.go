@@ -0,0 +1,113 @@
+package items
+
+import (
+	"context"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"nunchux/internal/shell"
+)
+
+// GoProvider offers test, build and generate tasks for the nearest Go module
+type GoProvider struct{}
+
+func (p *GoProvider) Name() string  { return "go" }
+func (p *GoProvider) Icon() string  { return "🐹" }
+func (p *GoProvider) Label() string { return "go" }
+
+func (p *GoProvider) Tasks(ctx context.Context, dir string) ([]TaskrunnerTask, error) {
+	if !hasCommand("go") {
+		return nil, nil
+	}
+	gomod := findUpward(dir, "go.mod")
+	if gomod == "" {
+		return nil, nil
+	}
+	modDir := filepath.Dir(gomod)
+
+	tasks := []TaskrunnerTask{
+		{TaskName: "test", Cmd: "go test ./...", Dir: modDir, Description: "Run all tests"},
+	}
+
+	if isGoMainPackage(modDir) {
+		tasks = append(tasks, TaskrunnerTask{TaskName: "build", Cmd: "go build .", Dir: modDir, Description: "Build the module's main package"})
+	}
+	entries, _ := os.ReadDir(filepath.Join(modDir, "cmd"))
+	for _, entry := range entries {
+		if entry.IsDir() && isGoMainPackage(filepath.Join(modDir, "cmd", entry.Name())) {
+			tasks = append(tasks, TaskrunnerTask{
+				TaskName:    entry.Name(),
+				Group:       "build",
+				Cmd:         "go build ./cmd/" + shell.Quote(entry.Name()),
+				Dir:         modDir,
+				Description: "Build cmd/" + entry.Name(),
+			})
+		}
+	}
+
+	tasks = append(tasks, TaskrunnerTask{TaskName: "generate", Cmd: "go generate ./...", Dir: modDir, Description: "Run go:generate directives"})
+	return tasks, nil
+}
+
+// SourceFiles returns the module's Go files and the cmd directories whose
+// package clauses decide the build tasks
+func (p *GoProvider) SourceFiles(dir string) []string {
+	gomod := findUpward(dir, "go.mod")
+	if gomod == "" {
+		return nil
+	}
+	modDir := filepath.Dir(gomod)
+	files := goFiles(modDir)
+	cmdDir := filepath.Join(modDir, "cmd")
+	files = append(files, cmdDir)
+	entries, _ := os.ReadDir(cmdDir)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			files = append(files, goFiles(filepath.Join(cmdDir, entry.Name()))...)
+		}
+	}
+	return files
+}
+
+// goFiles returns dir and the non-test Go files in it
+func goFiles(dir string) []string {
+	files := []string{dir}
+	entries, _ := os.ReadDir(dir)
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() && strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, "_test.go") {
+			files = append(files, filepath.Join(dir, name))
+		}
+	}
+	return files
+}
+
+// isGoMainPackage reports whether dir holds a non-test file of package main
+func isGoMainPackage(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		if goPackageName(filepath.Join(dir, name)) == "main" {
+			return true
+		}
+	}
+	return false
+}
+
+// goPackageName returns the package clause of a Go file
+func goPackageName(path string) string {
+	file, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.PackageClauseOnly)
+	if err != nil {
+		return ""
+	}
+	return file.Name.Name
+}
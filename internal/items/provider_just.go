@@ -0,0 +1,107 @@
+package items
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"nunchux/internal/shell"
+)
+
+// JustProvider lists the recipes of the nearest justfile, in the order
+// they are written in
+type JustProvider struct{}
+
+func (p *JustProvider) Name() string  { return "just" }
+func (p *JustProvider) Icon() string  { return "🤖" }
+func (p *JustProvider) Label() string { return "just" }
+
+// justDump is the part of `just --dump --dump-format json` we use
+type justDump struct {
+	Recipes map[string]struct {
+		Name       string          `json:"name"`
+		Doc        *string         `json:"doc"`
+		Private    bool            `json:"private"`
+		Parameters []justParameter `json:"parameters"`
+	} `json:"recipes"`
+}
+
+type justParameter struct {
+	Name    string          `json:"name"`
+	Kind    string          `json:"kind"`    // "singular", "plus" (1+ values) or "star" (0+ values)
+	Default json.RawMessage `json:"default"` // null, a string, or an expression
+}
+
+// param converts a recipe parameter. Defaults that are expressions rather
+// than plain strings are left for just to evaluate.
+func (p justParameter) param() TaskParam {
+	param := TaskParam{Name: p.Name, Kind: ParamPositional}
+	if len(p.Default) > 0 && string(p.Default) != "null" {
+		param.Optional = true
+		json.Unmarshal(p.Default, &param.Default)
+	}
+	switch p.Kind {
+	case "plus":
+		param.Kind = ParamRest
+	case "star":
+		param.Kind = ParamRest
+		param.Optional = true
+	}
+	return param
+}
+
+func (p *JustProvider) Tasks(ctx context.Context, dir string) ([]TaskrunnerTask, error) {
+	if !hasCommand("just") {
+		return nil, nil
+	}
+	// just searches upward itself; check first to avoid running it for nothing
+	if findUpward(dir, "justfile", "Justfile", ".justfile") == "" {
+		return nil, nil
+	}
+
+	output, err := providerOutput(ctx, dir, "just", "--dump", "--dump-format", "json")
+	if err != nil {
+		return nil, err
+	}
+	var dump justDump
+	if err := json.Unmarshal(output, &dump); err != nil {
+		return nil, err
+	}
+
+	var tasks []TaskrunnerTask
+	for name, recipe := range dump.Recipes {
+		if recipe.Private || strings.HasPrefix(name, "_") {
+			continue
+		}
+		task := TaskrunnerTask{TaskName: name, Cmd: "just " + shell.Quote(name)}
+		if recipe.Doc != nil {
+			task.Description = *recipe.Doc
+		}
+		for _, p := range recipe.Parameters {
+			task.Params = append(task.Params, p.param())
+		}
+		tasks = append(tasks, task)
+	}
+
+	// The dump sorts recipes by name; the summary keeps the justfile's order
+	order := make(map[string]int)
+	if summary, err := providerOutput(ctx, dir, "just", "--summary", "--unsorted"); err == nil {
+		for i, name := range strings.Fields(string(summary)) {
+			order[name] = i
+		}
+	}
+	rank := func(name string) int {
+		if i, ok := order[name]; ok {
+			return i
+		}
+		return len(order) // Missing from the summary: last, by name
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		if ri, rj := rank(tasks[i].TaskName), rank(tasks[j].TaskName); ri != rj {
+			return ri < rj
+		}
+		return tasks[i].TaskName < tasks[j].TaskName
+	})
+	return tasks, nil
+}
@@ -0,0 +1,257 @@
+package items
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"nunchux/internal/shell"
+)
+
+// MakeProvider lists the targets of the nearest Makefile. Descriptions come
+// from "## description" comments, either trailing the rule or on the line
+// above it. Overridable variables ("VAR ?= default") used in a target's
+// recipe become its parameters.
+type MakeProvider struct {
+	Includes bool // Also list targets from included makefiles
+}
+
+func (p *MakeProvider) Name() string  { return "make" }
+func (p *MakeProvider) Icon() string  { return "🔨" }
+func (p *MakeProvider) Label() string { return "make" }
+
+// makefileNames in the order make itself looks for them
+var makefileNames = []string{"GNUmakefile", "makefile", "Makefile"}
+
+// makeVarRef matches a variable reference: $(VAR) or ${VAR}
+var makeVarRef = regexp.MustCompile(`\$\(([A-Za-z_][A-Za-z0-9_]*)\)|\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// maxMakeIncludeDepth bounds nested includes
+const maxMakeIncludeDepth = 8
+
+func (p *MakeProvider) Tasks(ctx context.Context, dir string) ([]TaskrunnerTask, error) {
+	if !hasCommand("make") {
+		return nil, nil
+	}
+	path := findUpward(dir, makefileNames...)
+	if path == "" {
+		return nil, nil
+	}
+
+	parser := &makeParser{includes: p.Includes, seen: make(map[string]bool), vars: make(map[string]string)}
+	if err := parser.parseFile(path, 0); err != nil {
+		return nil, err
+	}
+
+	// make doesn't search upward, so run from the makefile's directory
+	makeDir := filepath.Dir(path)
+	var tasks []TaskrunnerTask
+	for _, target := range parser.targets {
+		task := TaskrunnerTask{
+			TaskName:    target.name,
+			Cmd:         "make " + shell.Quote(target.name),
+			Dir:         makeDir,
+			Description: target.desc,
+		}
+		for _, ref := range target.refs {
+			if value, ok := parser.vars[ref]; ok {
+				task.Params = append(task.Params, TaskParam{Name: ref, Default: value, Optional: true, Kind: ParamVariable})
+			}
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+// SourceFiles returns the included makefiles, when they are listed
+func (p *MakeProvider) SourceFiles(dir string) []string {
+	path := findUpward(dir, makefileNames...)
+	if !p.Includes || path == "" {
+		return nil
+	}
+	parser := &makeParser{includes: true, seen: make(map[string]bool), vars: make(map[string]string)}
+	parser.parseFile(path, 0)
+	var files []string
+	for key := range parser.seen {
+		if file, ok := strings.CutPrefix(key, "file:"); ok && file != path {
+			files = append(files, file)
+		}
+	}
+	slices.Sort(files)
+	return files
+}
+
+type makeTarget struct {
+	name string
+	desc string
+	refs []string // Variables referenced in the recipe
+}
+
+// makeParser collects targets across a makefile and its includes
+type makeParser struct {
+	includes bool
+	seen     map[string]bool // Files and targets already visited
+	targets  []makeTarget
+	vars     map[string]string // "?=" variables and their defaults
+}
+
+func (m *makeParser) parseFile(path string, depth int) error {
+	if m.seen["file:"+path] {
+		return nil
+	}
+	m.seen["file:"+path] = true
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var comment string // Pending "##" comment from the line above
+	var current []int  // Targets of the rule whose recipe is being read
+	inDefine := false
+	for _, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		// Recipe lines only contribute variable references
+		if strings.HasPrefix(line, "\t") {
+			for _, i := range current {
+				m.addRefs(&m.targets[i], line)
+			}
+			continue
+		}
+		if trimmed == "" {
+			comment = ""
+			continue
+		}
+		current = nil
+
+		// Skip multi-line variable definitions
+		if inDefine {
+			inDefine = trimmed != "endef"
+			continue
+		}
+		if strings.HasPrefix(trimmed, "define ") || trimmed == "define" {
+			inDefine = true
+			continue
+		}
+
+		if desc, ok := strings.CutPrefix(trimmed, "##"); ok {
+			comment = strings.TrimSpace(desc)
+			continue
+		}
+
+		if files, ok := makeIncludeFiles(trimmed); ok {
+			if m.includes && depth < maxMakeIncludeDepth {
+				m.parseIncludes(filepath.Dir(path), files, depth)
+			}
+			comment = ""
+			continue
+		}
+
+		if name, value, ok := strings.Cut(trimmed, "?="); ok && !strings.ContainsAny(name, ":$") {
+			m.vars[strings.TrimSpace(name)] = strings.TrimSpace(value)
+			comment = ""
+			continue
+		}
+
+		names, desc, ok := parseMakeRule(trimmed)
+		if !ok {
+			comment = ""
+			continue
+		}
+		if desc == "" {
+			desc = comment
+		}
+		comment = ""
+
+		for _, name := range names {
+			if m.seen["target:"+name] {
+				continue
+			}
+			m.seen["target:"+name] = true
+			m.targets = append(m.targets, makeTarget{name: name, desc: desc})
+			current = append(current, len(m.targets)-1)
+		}
+	}
+	return nil
+}
+
+// addRefs records the $(VAR) and ${VAR} references of a recipe line
+func (m *makeParser) addRefs(target *makeTarget, line string) {
+	for _, match := range makeVarRef.FindAllStringSubmatch(line, -1) {
+		name := match[1] + match[2]
+		if !slices.Contains(target.refs, name) {
+			target.refs = append(target.refs, name)
+		}
+	}
+}
+
+// parseIncludes parses included makefiles, relative to dir. Files named by
+// variables can't be resolved without make and are skipped.
+func (m *makeParser) parseIncludes(dir string, files []string, depth int) {
+	for _, file := range files {
+		if strings.Contains(file, "$") {
+			continue
+		}
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(dir, file)
+		}
+		matches, _ := filepath.Glob(file)
+		for _, match := range matches {
+			m.parseFile(match, depth+1) // Missing or unreadable includes are skipped
+		}
+	}
+}
+
+// makeIncludeFiles returns the files of an include, -include or sinclude
+// directive
+func makeIncludeFiles(line string) ([]string, bool) {
+	for _, directive := range []string{"include", "-include", "sinclude"} {
+		if rest, ok := strings.CutPrefix(line, directive+" "); ok {
+			return strings.Fields(rest), true
+		}
+	}
+	return nil, false
+}
+
+// parseMakeRule parses a rule line ("a b: deps ## desc") into its targets.
+// Pattern rules, special targets (.PHONY), targets named by variables and
+// target-specific variable assignments are not listed.
+func parseMakeRule(line string) ([]string, string, bool) {
+	if line == "" || strings.HasPrefix(line, "#") {
+		return nil, "", false
+	}
+
+	var desc string
+	if idx := strings.Index(line, "##"); idx >= 0 {
+		desc = strings.TrimSpace(line[idx+2:])
+		line = line[:idx]
+	}
+
+	idx := strings.Index(line, ":")
+	if idx <= 0 {
+		return nil, "", false
+	}
+	targets, rest := line[:idx], line[idx+1:]
+
+	// "x := y", "x ::= y", and assignments before the colon are variables
+	if strings.HasPrefix(rest, "=") || strings.HasPrefix(rest, ":=") || strings.Contains(targets, "=") {
+		return nil, "", false
+	}
+	// "target: VAR = value" is a target-specific variable, not a rule
+	if prereqs, _, _ := strings.Cut(rest, ";"); strings.Contains(prereqs, "=") {
+		return nil, "", false
+	}
+
+	var names []string
+	for _, name := range strings.Fields(targets) {
+		if strings.HasPrefix(name, ".") || strings.ContainsAny(name, "%$") {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names, desc, len(names) > 0
+}
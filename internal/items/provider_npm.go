@@ -0,0 +1,263 @@
+package items
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"nunchux/internal/shell"
+)
+
+// NpmProvider lists package.json scripts, run with the project's package
+// manager (npm, pnpm, yarn or bun). In a workspace (monorepo) it lists the
+// scripts of the root and of every workspace package, labelled
+// "<package> › <script>".
+type NpmProvider struct {
+	Args bool // Prompt for "-- args" to pass to scripts
+}
+
+func (p *NpmProvider) Name() string  { return "npm" }
+func (p *NpmProvider) Icon() string  { return "📦" }
+func (p *NpmProvider) Label() string { return "npm" }
+
+// packageJSON is the part of package.json we use
+type packageJSON struct {
+	Name           string            `json:"name"`
+	Scripts        map[string]string `json:"scripts"`
+	PackageManager string            `json:"packageManager"`
+	Workspaces     json.RawMessage   `json:"workspaces"` // ["a/*"] or {"packages": ["a/*"]}
+}
+
+// packageManagerLockfiles detects the package manager when package.json
+// doesn't name one
+var packageManagerLockfiles = []struct {
+	file    string
+	manager string
+}{
+	{"pnpm-lock.yaml", "pnpm"},
+	{"yarn.lock", "yarn"},
+	{"bun.lockb", "bun"},
+	{"bun.lock", "bun"},
+	{"package-lock.json", "npm"},
+}
+
+func (p *NpmProvider) Tasks(ctx context.Context, dir string) ([]TaskrunnerTask, error) {
+	path := findUpward(dir, "package.json")
+	if path == "" {
+		return nil, nil
+	}
+
+	rootDir, root, err := npmWorkspaceRoot(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+	manager := packageManager(rootDir, root)
+	if !hasCommand(manager) {
+		return nil, nil
+	}
+
+	tasks := npmScriptTasks(manager, rootDir, "", root)
+
+	members := npmWorkspaceMembers(rootDir, root)
+	sort.Slice(members, func(i, j int) bool { return members[i].label < members[j].label })
+	for _, member := range members {
+		tasks = append(tasks, npmScriptTasks(manager, member.dir, member.label, member.pkg)...)
+	}
+
+	if p.Args {
+		for i := range tasks {
+			tasks[i].Params = []TaskParam{{Name: "args", Optional: true, Kind: ParamRest, Separator: "--"}}
+		}
+	}
+	return tasks, nil
+}
+
+// SourceFiles returns the package.json files of the workspace members and
+// the directories they are found in
+func (p *NpmProvider) SourceFiles(dir string) []string {
+	path := findUpward(dir, "package.json")
+	if path == "" {
+		return nil
+	}
+	rootDir, root, err := npmWorkspaceRoot(filepath.Dir(path))
+	if err != nil {
+		return nil
+	}
+	var files []string
+	for _, member := range npmWorkspaceMembers(rootDir, root) {
+		files = append(files, filepath.Join(member.dir, "package.json"), filepath.Dir(member.dir))
+	}
+	return files
+}
+
+// npmScriptTasks lists a package's scripts, grouped under label when set.
+// Scripts run from the package directory, since "npm run" doesn't search
+// upward.
+func npmScriptTasks(manager, dir, label string, pkg *packageJSON) []TaskrunnerTask {
+	var names []string
+	for name := range pkg.Scripts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var tasks []TaskrunnerTask
+	for _, name := range names {
+		tasks = append(tasks, TaskrunnerTask{
+			TaskName: name,
+			Group:    label,
+			Cmd:      manager + " run " + shell.Quote(name),
+			Dir:      dir,
+		})
+	}
+	return tasks
+}
+
+// readPackageJSON reads dir/package.json
+func readPackageJSON(dir string) (*packageJSON, error) {
+	path := filepath.Join(dir, "package.json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var pkg packageJSON
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &pkg, nil
+}
+
+// npmWorkspaceRoot returns the workspace root containing pkgDir, or pkgDir
+// itself when it isn't part of a workspace
+func npmWorkspaceRoot(pkgDir string) (string, *packageJSON, error) {
+	pkg, err := readPackageJSON(pkgDir)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(npmWorkspacePatterns(pkgDir, pkg)) > 0 {
+		return pkgDir, pkg, nil
+	}
+
+	for d := filepath.Dir(pkgDir); ; d = filepath.Dir(d) {
+		if candidate, err := readPackageJSON(d); err == nil {
+			for _, member := range npmWorkspaceMembers(d, candidate) {
+				if member.dir == pkgDir {
+					return d, candidate, nil
+				}
+			}
+		}
+		if parent := filepath.Dir(d); parent == d {
+			return pkgDir, pkg, nil
+		}
+	}
+}
+
+// packageManager returns the package manager for a project root
+func packageManager(rootDir string, root *packageJSON) string {
+	// "packageManager": "pnpm@8.6.0"
+	if name, _, _ := strings.Cut(root.PackageManager, "@"); name != "" {
+		return name
+	}
+	for _, lock := range packageManagerLockfiles {
+		if _, err := os.Stat(filepath.Join(rootDir, lock.file)); err == nil {
+			return lock.manager
+		}
+	}
+	return "npm"
+}
+
+// npmMember is a workspace package
+type npmMember struct {
+	dir   string
+	label string
+	pkg   *packageJSON
+}
+
+// npmWorkspaceMembers lists the workspace packages of a root
+func npmWorkspaceMembers(rootDir string, root *packageJSON) []npmMember {
+	var include, exclude []string
+	for _, pattern := range npmWorkspacePatterns(rootDir, root) {
+		if negated, ok := strings.CutPrefix(pattern, "!"); ok {
+			exclude = append(exclude, expandWorkspacePattern(rootDir, negated)...)
+		} else {
+			include = append(include, expandWorkspacePattern(rootDir, pattern)...)
+		}
+	}
+
+	excluded := make(map[string]bool)
+	for _, dir := range exclude {
+		excluded[dir] = true
+	}
+
+	var members []npmMember
+	seen := make(map[string]bool)
+	for _, dir := range include {
+		if dir == rootDir || excluded[dir] || seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		pkg, err := readPackageJSON(dir)
+		if err != nil {
+			continue
+		}
+		members = append(members, npmMember{dir: dir, label: npmPackageLabel(dir, pkg), pkg: pkg})
+	}
+	return members
+}
+
+// npmWorkspacePatterns returns the workspace globs from package.json or,
+// for pnpm, pnpm-workspace.yaml
+func npmWorkspacePatterns(rootDir string, root *packageJSON) []string {
+	if len(root.Workspaces) > 0 {
+		var patterns []string
+		if json.Unmarshal(root.Workspaces, &patterns) == nil {
+			return patterns
+		}
+		var object struct {
+			Packages []string `json:"packages"`
+		}
+		if json.Unmarshal(root.Workspaces, &object) == nil {
+			return object.Packages
+		}
+	}
+	if data, err := os.ReadFile(filepath.Join(rootDir, "pnpm-workspace.yaml")); err == nil {
+		return yamlList(string(data), "packages")
+	}
+	return nil
+}
+
+// expandWorkspacePattern expands a workspace glob into directories. A
+// trailing "/**" matches up to two levels deep.
+func expandWorkspacePattern(rootDir, pattern string) []string {
+	pattern = strings.TrimPrefix(strings.TrimSuffix(pattern, "/"), "./")
+	patterns := []string{pattern}
+	if base, ok := strings.CutSuffix(pattern, "/**"); ok {
+		patterns = []string{base + "/*", base + "/*/*"}
+	}
+
+	var dirs []string
+	for _, p := range patterns {
+		matches, _ := filepath.Glob(filepath.Join(rootDir, p))
+		for _, match := range matches {
+			if info, err := os.Stat(match); err == nil && info.IsDir() && !strings.Contains(match, "node_modules") {
+				dirs = append(dirs, match)
+			}
+		}
+	}
+	return dirs
+}
+
+// npmPackageLabel labels a workspace package by its unscoped name, or its
+// directory name
+func npmPackageLabel(dir string, pkg *packageJSON) string {
+	if pkg.Name == "" {
+		return filepath.Base(dir)
+	}
+	if idx := strings.LastIndex(pkg.Name, "/"); idx >= 0 {
+		return pkg.Name[idx+1:]
+	}
+	return pkg.Name
+}
@@ -0,0 +1,136 @@
+package items
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"nunchux/internal/shell"
+)
+
+// PythonProvider lists the scripts declared in the nearest pyproject.toml:
+// [project.scripts] entry points and poetry, hatch and pdm scripts
+type PythonProvider struct{}
+
+func (p *PythonProvider) Name() string  { return "python" }
+func (p *PythonProvider) Icon() string  { return "🐍" }
+func (p *PythonProvider) Label() string { return "python" }
+
+func (p *PythonProvider) Tasks(ctx context.Context, dir string) ([]TaskrunnerTask, error) {
+	path := findUpward(dir, "pyproject.toml")
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	doc := parseTOML(string(data))
+	projectDir := filepath.Dir(path)
+
+	var tasks []TaskrunnerTask
+	seen := make(map[string]bool)
+	add := func(tool, name, run, desc string) {
+		if seen[name] || (tool != "" && !hasCommand(tool)) {
+			return
+		}
+		seen[name] = true
+		tasks = append(tasks, TaskrunnerTask{
+			TaskName:    name,
+			Cmd:         run,
+			Dir:         projectDir,
+			Description: desc,
+		})
+	}
+
+	// Entry points run through the project's environment manager
+	tool := pythonTool(projectDir, doc)
+	for _, table := range []string{"project.scripts", "tool.poetry.scripts"} {
+		scripts := tomlFind(doc, table)
+		if scripts == nil {
+			continue
+		}
+		scriptTool := tool
+		if table == "tool.poetry.scripts" {
+			scriptTool = "poetry"
+		}
+		for _, name := range scripts.Keys {
+			run := shell.Quote(name)
+			if scriptTool != "" {
+				run = scriptTool + " run " + run
+			}
+			add(scriptTool, name, run, pythonScriptDesc(scripts.Values[name]))
+		}
+	}
+
+	// pdm scripts; "_" holds shared settings
+	if scripts := tomlFind(doc, "tool.pdm.scripts"); scripts != nil {
+		for _, name := range scripts.Keys {
+			if name == "_" {
+				continue
+			}
+			add("pdm", name, "pdm run "+shell.Quote(name), pythonScriptDesc(scripts.Values[name]))
+		}
+	}
+
+	// hatch scripts, per environment
+	for _, table := range doc {
+		env, ok := strings.CutPrefix(table.Name, "tool.hatch.envs.")
+		if !ok {
+			continue
+		}
+		env, ok = strings.CutSuffix(env, ".scripts")
+		if !ok {
+			continue
+		}
+		for _, name := range table.Keys {
+			taskName := name
+			if env != "default" {
+				taskName = env + ":" + name
+			}
+			add("hatch", taskName, "hatch run "+shell.Quote(taskName), pythonScriptDesc(table.Values[name]))
+		}
+	}
+
+	return tasks, nil
+}
+
+// pythonTool returns the environment manager a project uses, or "" to run
+// entry points directly from the active environment
+func pythonTool(projectDir string, doc []tomlTable) string {
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(projectDir, name))
+		return err == nil
+	}
+	switch {
+	case exists("poetry.lock") || tomlFind(doc, "tool.poetry") != nil:
+		return "poetry"
+	case exists("pdm.lock") || tomlFind(doc, "tool.pdm") != nil:
+		return "pdm"
+	case exists("uv.lock"):
+		return "uv"
+	case tomlFind(doc, "tool.hatch.envs.default") != nil:
+		return "hatch"
+	}
+	return ""
+}
+
+// pythonScriptDesc describes a script from its value: an entry point, a
+// command, a list of commands or a table with help or cmd
+func pythonScriptDesc(raw string) string {
+	if s := tomlString(raw); s != "" {
+		return s
+	}
+	if list := tomlStrings(raw); list != nil {
+		return strings.Join(list, "; ")
+	}
+	if table := tomlInline(raw); table != nil {
+		for _, key := range []string{"help", "cmd", "shell", "call", "reference", "callable"} {
+			if s := tomlString(table[key]); s != "" {
+				return s
+			}
+		}
+	}
+	return ""
+}
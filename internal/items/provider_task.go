@@ -0,0 +1,98 @@
+package items
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+
+	"nunchux/internal/shell"
+)
+
+// TaskfileProvider lists the tasks of the nearest Taskfile
+type TaskfileProvider struct{}
+
+func (p *TaskfileProvider) Name() string  { return "task" }
+func (p *TaskfileProvider) Icon() string  { return "✓" }
+func (p *TaskfileProvider) Label() string { return "task" }
+
+var taskfileNames = []string{
+	"Taskfile.yml", "taskfile.yml", "Taskfile.yaml", "taskfile.yaml",
+	"Taskfile.dist.yml", "taskfile.dist.yml", "Taskfile.dist.yaml", "taskfile.dist.yaml",
+}
+
+func (p *TaskfileProvider) Tasks(ctx context.Context, dir string) ([]TaskrunnerTask, error) {
+	// Support both 'task' and 'go-task' binary names
+	bin := "task"
+	if !hasCommand(bin) {
+		bin = "go-task"
+		if !hasCommand(bin) {
+			return nil, nil
+		}
+	}
+
+	path := findUpward(dir, taskfileNames...)
+	if path == "" {
+		return nil, nil
+	}
+
+	// Reading the Taskfile is much faster than running task, but only task
+	// itself can resolve included Taskfiles
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if content := string(data); !yamlHasKey(content, "includes") {
+		return parseTaskfile(content, bin), nil
+	}
+	return p.listJSON(ctx, dir, bin)
+}
+
+// parseTaskfile lists the non-internal tasks of a Taskfile. Variables a
+// task requires become its parameters.
+func parseTaskfile(content, bin string) []TaskrunnerTask {
+	var tasks []TaskrunnerTask
+	for _, entry := range yamlMapping(content, "tasks") {
+		if entry.Scalar("internal") == "true" {
+			continue
+		}
+		task := TaskrunnerTask{
+			TaskName:    entry.Key,
+			Cmd:         bin + " " + shell.Quote(entry.Key),
+			Description: entry.Scalar("desc"),
+		}
+		for _, name := range entry.Child("requires").List("vars") {
+			name = strings.TrimPrefix(name, "name: ") // "- name: ENV" with enum
+			task.Params = append(task.Params, TaskParam{Name: name, Kind: ParamVariable})
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks
+}
+
+// listJSON asks task for the full task list
+func (p *TaskfileProvider) listJSON(ctx context.Context, dir, bin string) ([]TaskrunnerTask, error) {
+	output, err := providerOutput(ctx, dir, bin, "--list-all", "--json")
+	if err != nil {
+		return nil, err
+	}
+	var list struct {
+		Tasks []struct {
+			Name string `json:"name"`
+			Desc string `json:"desc"`
+		} `json:"tasks"`
+	}
+	if err := json.Unmarshal(output, &list); err != nil {
+		return nil, err
+	}
+
+	var tasks []TaskrunnerTask
+	for _, t := range list.Tasks {
+		tasks = append(tasks, TaskrunnerTask{
+			TaskName:    t.Name,
+			Cmd:         bin + " " + shell.Quote(t.Name),
+			Description: t.Desc,
+		})
+	}
+	return tasks, nil
+}
@@ -0,0 +1,612 @@
+package items
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"nunchux/internal/config"
+)
+
+// fakeBin puts executable scripts on PATH for the duration of a test
+func fakeBin(t *testing.T, scripts map[string]string) {
+	t.Helper()
+	dir := t.TempDir()
+	for name, body := range scripts {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+body), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir)
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func taskNames(tasks []TaskrunnerTask) []string {
+	var names []string
+	for _, task := range tasks {
+		names = append(names, task.FullName())
+	}
+	return names
+}
+
+func TestYamlMapping(t *testing.T) {
+	content := `version: '3'
+
+# Comment
+tasks:
+  build:
+    desc: Build it   # trailing comment
+    cmds:
+      - go build
+  "docker:push":
+    desc: "Push: the image"
+  lint: golangci-lint run
+  helper:
+    internal: true
+vars:
+  X: 1
+`
+	entries := yamlMapping(content, "tasks")
+	var keys []string
+	for _, e := range entries {
+		keys = append(keys, e.Key)
+	}
+	if want := []string{"build", "docker:push", "lint", "helper"}; !reflect.DeepEqual(keys, want) {
+		t.Fatalf("keys = %v, want %v", keys, want)
+	}
+	if got := entries[0].Scalar("desc"); got != "Build it" {
+		t.Errorf("build desc = %q", got)
+	}
+	if got := entries[1].Scalar("desc"); got != "Push: the image" {
+		t.Errorf("docker:push desc = %q", got)
+	}
+	if got := entries[2].Value; got != "golangci-lint run" {
+		t.Errorf("lint value = %q", got)
+	}
+	if !yamlHasKey(content, "vars") || yamlHasKey(content, "includes") {
+		t.Error("yamlHasKey mismatch")
+	}
+}
+
+func TestJustProvider(t *testing.T) {
+	fakeBin(t, map[string]string{"just": `[ "$1" = --summary ] && { echo 'test build'; exit; }
+echo '{"recipes": {` +
+		`"test": {"name": "test", "doc": "Run tests", "private": false},` +
+		`"build": {"name": "build", "doc": null, "private": false},` +
+		`"_setup": {"name": "_setup", "doc": null, "private": false},` +
+		`"secret": {"name": "secret", "doc": null, "private": true}}}'`})
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "justfile"), "build:\n\techo\n")
+	sub := filepath.Join(dir, "src")
+	os.Mkdir(sub, 0755)
+
+	tasks, err := (&JustProvider{}).Tasks(context.Background(), sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// In the justfile's order, not the dump's
+	want := []TaskrunnerTask{
+		{TaskName: "test", Cmd: "just 'test'", Description: "Run tests"},
+		{TaskName: "build", Cmd: "just 'build'"},
+	}
+	if !reflect.DeepEqual(tasks, want) {
+		t.Errorf("tasks = %+v, want %+v", tasks, want)
+	}
+}
+
+func TestNpmProvider(t *testing.T) {
+	fakeBin(t, map[string]string{"npm": "exit 0\n"})
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "package.json"), `{"scripts": {"test": "jest", "build": "tsc"}}`)
+
+	tasks, err := (&NpmProvider{}).Tasks(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := taskNames(tasks); !reflect.DeepEqual(got, []string{"build", "test"}) {
+		t.Errorf("tasks = %v", got)
+	}
+	if tasks[0].Cmd != "npm run 'build'" || tasks[0].Dir != dir {
+		t.Errorf("cmd = %q in %q", tasks[0].Cmd, tasks[0].Dir)
+	}
+}
+
+func TestTaskfileProvider(t *testing.T) {
+	fakeBin(t, map[string]string{"task": `echo '{"tasks": [{"name": "lib:build", "desc": "Included"}]}'`})
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "Taskfile.yml"), "tasks:\n  build:\n    desc: Build\n  gen:\n    internal: true\n")
+	tasks, err := (&TaskfileProvider{}).Tasks(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []TaskrunnerTask{{TaskName: "build", Cmd: "task 'build'", Description: "Build"}}
+	if !reflect.DeepEqual(tasks, want) {
+		t.Errorf("parsed tasks = %+v, want %+v", tasks, want)
+	}
+
+	// Includes need task itself
+	writeFile(t, filepath.Join(dir, "Taskfile.yml"), "includes:\n  lib: ./lib\ntasks:\n  build: go build\n")
+	tasks, err = (&TaskfileProvider{}).Tasks(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := taskNames(tasks); !reflect.DeepEqual(got, []string{"lib:build"}) {
+		t.Errorf("json tasks = %v", got)
+	}
+}
+
+func TestProviderMissingTool(t *testing.T) {
+	fakeBin(t, nil)
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "package.json"), `{"scripts": {"build": "tsc"}}`)
+
+	tasks, err := (&NpmProvider{}).Tasks(context.Background(), dir)
+	if err != nil || tasks != nil {
+		t.Errorf("Tasks without npm = %v, %v; want nothing", tasks, err)
+	}
+}
+
+func TestScriptProvider(t *testing.T) {
+	binDir := t.TempDir()
+	writeFile(t, filepath.Join(binDir, "taskrunners", "custom.sh"), `
+plugin_icon() { echo "*"; }
+plugin_label() { echo "Custom"; }
+plugin_items() { printf 'deploy\tmake deploy\tShip it\n'; }
+`)
+
+	provider := FindTaskProvider(config.TaskrunnerConfig{Name: "custom"}, binDir)
+	if provider == nil {
+		t.Fatal("custom provider not found")
+	}
+	if provider.Icon() != "*" || provider.Label() != "Custom" {
+		t.Errorf("icon, label = %q, %q", provider.Icon(), provider.Label())
+	}
+	tasks, err := provider.Tasks(context.Background(), t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []TaskrunnerTask{{TaskName: "deploy", Cmd: "make deploy", Description: "Ship it"}}
+	if !reflect.DeepEqual(tasks, want) {
+		t.Errorf("tasks = %+v, want %+v", tasks, want)
+	}
+}
+
+func TestMakeProvider(t *testing.T) {
+	fakeBin(t, map[string]string{"make": "exit 0\n"})
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "Makefile"), `CC := gcc
+VERSION ?= 1.0
+.PHONY: build test
+
+## Build the binary
+build: deps
+	$(CC) -o app main.c
+
+test: build ## Run the tests
+	./app --test
+
+%.o: %.c
+	$(CC) -c $<
+
+debug: CFLAGS += -g
+_internal install: ; @echo install
+
+define HELP
+fake: target
+endef
+
+$(BIN): build
+
+include extra.mk
+-include missing.mk
+`)
+	writeFile(t, filepath.Join(dir, "extra.mk"), "lint: ## Lint everything\n\tgolangci-lint run\n")
+
+	tasks, err := (&MakeProvider{}).Tasks(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []TaskrunnerTask{
+		{TaskName: "build", Cmd: "make 'build'", Dir: dir, Description: "Build the binary"},
+		{TaskName: "test", Cmd: "make 'test'", Dir: dir, Description: "Run the tests"},
+		{TaskName: "_internal", Cmd: "make '_internal'", Dir: dir},
+		{TaskName: "install", Cmd: "make 'install'", Dir: dir},
+	}
+	if !reflect.DeepEqual(tasks, want) {
+		t.Errorf("tasks = %+v\nwant %+v", tasks, want)
+	}
+
+	tasks, err = (&MakeProvider{Includes: true}).Tasks(context.Background(), filepath.Join(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := taskNames(tasks); !reflect.DeepEqual(got, []string{"build", "test", "_internal", "install", "lint"}) {
+		t.Errorf("tasks with includes = %v", got)
+	}
+}
+
+func TestParseTOML(t *testing.T) {
+	doc := parseTOML(`name = "root" # comment
+[workspace]
+members = [
+    "crates/*",  # all crates
+    "cli",
+]
+
+[[bin]]
+name = "one"
+
+[[bin]]
+name = 'two'
+
+[tool.pdm.scripts]
+lint = { cmd = "ruff check", help = "Lint # the code" }
+"my-script" = """
+echo hi
+"""
+`)
+	if got := tomlFind(doc, "").String("name"); got != "root" {
+		t.Errorf("root name = %q", got)
+	}
+	if got := tomlStrings(tomlFind(doc, "workspace").Values["members"]); !reflect.DeepEqual(got, []string{"crates/*", "cli"}) {
+		t.Errorf("members = %v", got)
+	}
+	bins := tomlFindAll(doc, "bin")
+	if len(bins) != 2 || bins[0].String("name") != "one" || bins[1].String("name") != "two" {
+		t.Errorf("bins = %+v", bins)
+	}
+	scripts := tomlFind(doc, "tool.pdm.scripts")
+	if !reflect.DeepEqual(scripts.Keys, []string{"lint", "my-script"}) {
+		t.Errorf("script keys = %v", scripts.Keys)
+	}
+	if got := tomlString(tomlInline(scripts.Values["lint"])["help"]); got != "Lint # the code" {
+		t.Errorf("lint help = %q", got)
+	}
+	if got := scripts.String("my-script"); got != "echo hi\n" {
+		t.Errorf("my-script = %q", got)
+	}
+}
+
+func TestCargoProvider(t *testing.T) {
+	fakeBin(t, map[string]string{"cargo": "exit 0\n"})
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "Cargo.toml"), "[workspace]\nmembers = [\"crates/*\"]\n")
+	writeFile(t, filepath.Join(root, "crates", "cli", "Cargo.toml"), "[package]\nname = \"mycli\"\n")
+	writeFile(t, filepath.Join(root, "crates", "cli", "src", "main.rs"), "fn main() {}\n")
+	writeFile(t, filepath.Join(root, "crates", "cli", "src", "bin", "helper.rs"), "fn main() {}\n")
+	writeFile(t, filepath.Join(root, "crates", "core", "Cargo.toml"), "[package]\nname = \"core\"\n")
+
+	// Run from inside a member: tasks cover the whole workspace
+	tasks, err := (&CargoProvider{}).Tasks(context.Background(), filepath.Join(root, "crates", "core"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"build", "test", "run › mycli", "run › helper", "mycli › build", "mycli › test", "core › build", "core › test"}
+	if got := taskNames(tasks); !reflect.DeepEqual(got, want) {
+		t.Errorf("tasks = %v\nwant %v", got, want)
+	}
+	if want := "cargo run -p 'mycli' --bin 'helper'"; tasks[3].Cmd != want || tasks[3].Dir != root {
+		t.Errorf("run cmd = %q in %q, want %q in %q", tasks[3].Cmd, tasks[3].Dir, want, root)
+	}
+}
+
+func TestGoProvider(t *testing.T) {
+	fakeBin(t, map[string]string{"go": "exit 0\n"})
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "go.mod"), "module example.com/x\n")
+	writeFile(t, filepath.Join(root, "lib.go"), "package x\n")
+	writeFile(t, filepath.Join(root, "cmd", "server", "main.go"), "// Command server\npackage main\n")
+	writeFile(t, filepath.Join(root, "cmd", "shared", "util.go"), "package shared\n")
+
+	tasks, err := (&GoProvider{}).Tasks(context.Background(), filepath.Join(root, "cmd"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := taskNames(tasks); !reflect.DeepEqual(got, []string{"test", "build › server", "generate"}) {
+		t.Errorf("tasks = %v", got)
+	}
+	if tasks[1].Cmd != "go build ./cmd/'server'" || tasks[1].Dir != root {
+		t.Errorf("build cmd = %q in %q", tasks[1].Cmd, tasks[1].Dir)
+	}
+}
+
+func TestPythonProvider(t *testing.T) {
+	fakeBin(t, map[string]string{"poetry": "exit 0\n"})
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "pyproject.toml"), `[project]
+name = "app"
+
+[project.scripts]
+serve = "app.main:serve"
+
+[tool.poetry.scripts]
+migrate = "app.db:migrate"
+
+[tool.pdm.scripts]
+lint = "ruff check"
+`)
+	writeFile(t, filepath.Join(root, "poetry.lock"), "")
+
+	tasks, err := (&PythonProvider{}).Tasks(context.Background(), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// pdm isn't installed, so its scripts are left out
+	want := []TaskrunnerTask{
+		{TaskName: "serve", Cmd: "poetry run 'serve'", Dir: root, Description: "app.main:serve"},
+		{TaskName: "migrate", Cmd: "poetry run 'migrate'", Dir: root, Description: "app.db:migrate"},
+	}
+	if !reflect.DeepEqual(tasks, want) {
+		t.Errorf("tasks = %+v\nwant %+v", tasks, want)
+	}
+}
+
+func TestComposeProvider(t *testing.T) {
+	fakeBin(t, nil) // Listing must not need docker
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "docker-compose.yml"), `services:
+  web:
+    image: nginx:latest
+    ports:
+      - "80:80"
+  db:
+    build: ./db
+volumes:
+  data:
+`)
+
+	tasks, err := (&ComposeProvider{}).Tasks(context.Background(), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"web › up", "web › logs -f", "web › restart", "web › exec sh",
+		"db › up", "db › logs -f", "db › restart", "db › exec sh",
+	}
+	if got := taskNames(tasks); !reflect.DeepEqual(got, want) {
+		t.Errorf("tasks = %v", got)
+	}
+	if want := "docker compose exec 'web' sh"; tasks[3].Cmd != want || tasks[3].Dir != root {
+		t.Errorf("exec cmd = %q in %q, want %q in %q", tasks[3].Cmd, tasks[3].Dir, want, root)
+	}
+	if tasks[0].Description != "nginx:latest" {
+		t.Errorf("description = %q", tasks[0].Description)
+	}
+}
+
+func TestNpmProviderWorkspaces(t *testing.T) {
+	fakeBin(t, map[string]string{"pnpm": "exit 0\n"})
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "package.json"), `{"scripts": {"lint": "eslint ."}}`)
+	writeFile(t, filepath.Join(root, "pnpm-lock.yaml"), "")
+	writeFile(t, filepath.Join(root, "pnpm-workspace.yaml"), "packages:\n  - 'apps/*'\n  - '!apps/legacy'\n")
+	writeFile(t, filepath.Join(root, "apps", "web", "package.json"), `{"name": "@acme/web", "scripts": {"build": "vite build", "dev": "vite"}}`)
+	writeFile(t, filepath.Join(root, "apps", "api", "package.json"), `{"scripts": {"start": "node ."}}`)
+	writeFile(t, filepath.Join(root, "apps", "legacy", "package.json"), `{"scripts": {"old": "true"}}`)
+
+	// From inside a package, the whole workspace is listed
+	tasks, err := (&NpmProvider{}).Tasks(context.Background(), filepath.Join(root, "apps", "web"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"lint", "api › start", "web › build", "web › dev"}
+	if got := taskNames(tasks); !reflect.DeepEqual(got, want) {
+		t.Errorf("tasks = %v, want %v", got, want)
+	}
+	if want := filepath.Join(root, "apps", "web"); tasks[2].Cmd != "pnpm run 'build'" || tasks[2].Dir != want {
+		t.Errorf("cmd = %q in %q, want %q", tasks[2].Cmd, tasks[2].Dir, want)
+	}
+}
+
+func TestPackageManager(t *testing.T) {
+	dir := t.TempDir()
+	if got := packageManager(dir, &packageJSON{}); got != "npm" {
+		t.Errorf("default = %q", got)
+	}
+	writeFile(t, filepath.Join(dir, "yarn.lock"), "")
+	if got := packageManager(dir, &packageJSON{}); got != "yarn" {
+		t.Errorf("with yarn.lock = %q", got)
+	}
+	if got := packageManager(dir, &packageJSON{PackageManager: "bun@1.1.0"}); got != "bun" {
+		t.Errorf("with packageManager field = %q", got)
+	}
+}
+
+func TestTaskCommand(t *testing.T) {
+	task := TaskrunnerTask{Cmd: "just deploy", Params: []TaskParam{
+		{Name: "env"},
+		{Name: "region", Default: "eu", Optional: true},
+		{Name: "flags", Optional: true, Kind: ParamRest},
+	}}
+	tests := []struct {
+		values []string
+		want   string
+	}{
+		{[]string{"prod", "us", "--dry-run -v"}, "just deploy 'prod' 'us' --dry-run -v"},
+		{[]string{"it's"}, `just deploy 'it'"'"'s'`},
+		{[]string{"prod", "", "-v"}, "just deploy 'prod' 'eu' -v"}, // The default fills the gap
+		{[]string{"prod", "", ""}, "just deploy 'prod'"},
+	}
+	for _, tt := range tests {
+		if got, err := task.Command(tt.values); err != nil || got != tt.want {
+			t.Errorf("Command(%q) = %q, %v, want %q", tt.values, got, err, tt.want)
+		}
+	}
+
+	// Without a default, a gap before a later value cannot be filled
+	noDefault := TaskrunnerTask{Cmd: "just deploy", Params: []TaskParam{
+		{Name: "region", Optional: true},
+		{Name: "tier", Optional: true},
+		{Name: "VERBOSE", Optional: true, Kind: ParamVariable},
+	}}
+	if got, err := noDefault.Command([]string{"", "gold"}); err == nil {
+		t.Errorf("Command with a gap = %q, want an error", got)
+	}
+	if got, err := noDefault.Command([]string{"", "", "1"}); err != nil || got != "just deploy VERBOSE='1'" {
+		t.Errorf("Command with only a variable set = %q, %v", got, err)
+	}
+	if got, err := noDefault.Command([]string{"us", "", "1"}); err != nil || got != "just deploy 'us' VERBOSE='1'" {
+		t.Errorf("Command with a trailing gap = %q, %v", got, err)
+	}
+
+	vars := TaskrunnerTask{Cmd: "make build", Params: []TaskParam{
+		{Name: "VERSION", Optional: true, Kind: ParamVariable},
+		{Name: "OUT", Kind: ParamVariable},
+	}}
+	if got, _ := vars.Command([]string{"", "bin"}); got != "make build OUT='bin'" {
+		t.Errorf("variables = %q", got)
+	}
+
+	npm := TaskrunnerTask{Cmd: "npm run test", Params: []TaskParam{{Name: "args", Optional: true, Kind: ParamRest, Separator: "--"}}}
+	if got, _ := npm.Command([]string{"--watch"}); got != "npm run test -- --watch" {
+		t.Errorf("rest = %q", got)
+	}
+}
+
+func TestProviderParams(t *testing.T) {
+	fakeBin(t, map[string]string{
+		"just": `echo '{"recipes": {"deploy": {"name": "deploy", "parameters": [` +
+			`{"name": "env", "kind": "singular", "default": null},` +
+			`{"name": "region", "kind": "singular", "default": "eu"},` +
+			`{"name": "rest", "kind": "star", "default": null}]}}}'`,
+		"make": "exit 0\n",
+		"task": "exit 0\n",
+	})
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "justfile"), "")
+	writeFile(t, filepath.Join(dir, "Makefile"), "VERSION ?= 1.0\nCC := gcc\n\nbuild:\n\t$(CC) -DV=$(VERSION) main.c\n\nclean:\n\trm -f app\n")
+	writeFile(t, filepath.Join(dir, "Taskfile.yml"), "tasks:\n  release:\n    requires:\n      vars: [TAG]\n")
+
+	just, _ := (&JustProvider{}).Tasks(context.Background(), dir)
+	wantJust := []TaskParam{
+		{Name: "env"},
+		{Name: "region", Default: "eu", Optional: true},
+		{Name: "rest", Optional: true, Kind: ParamRest},
+	}
+	if len(just) != 1 || !reflect.DeepEqual(just[0].Params, wantJust) {
+		t.Errorf("just params = %+v", just)
+	}
+
+	makeTasks, _ := (&MakeProvider{}).Tasks(context.Background(), dir)
+	wantMake := []TaskParam{{Name: "VERSION", Default: "1.0", Optional: true, Kind: ParamVariable}}
+	if len(makeTasks) != 2 || !reflect.DeepEqual(makeTasks[0].Params, wantMake) || makeTasks[1].Params != nil {
+		t.Errorf("make params = %+v", makeTasks)
+	}
+
+	taskTasks, _ := (&TaskfileProvider{}).Tasks(context.Background(), dir)
+	if len(taskTasks) != 1 || !reflect.DeepEqual(taskTasks[0].Params, []TaskParam{{Name: "TAG", Kind: ParamVariable}}) {
+		t.Errorf("task params = %+v", taskTasks)
+	}
+}
+
+func TestInlineProvider(t *testing.T) {
+	dir := t.TempDir()
+	cfg := config.TaskrunnerConfig{Name: "mytool", Options: map[string]string{
+		"list_cmd":     `printf 'build\ntest\t\tRun tests\n\tnameless\nlint\tmytool check\tLint it\n' && pwd >&2`,
+		"run_template": "mytool run {task}",
+	}}
+	provider := FindTaskProvider(cfg, "")
+	if _, ok := provider.(*InlineProvider); !ok {
+		t.Fatalf("provider = %T, want *InlineProvider", provider)
+	}
+
+	tasks, err := provider.Tasks(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []TaskrunnerTask{
+		{TaskName: "build", Cmd: "mytool run 'build'"},
+		{TaskName: "test", Cmd: "mytool run 'test'", Description: "Run tests"},
+		{TaskName: "lint", Cmd: "mytool check", Description: "Lint it"},
+	}
+	if !reflect.DeepEqual(tasks, want) {
+		t.Errorf("tasks = %+v", tasks)
+	}
+}
+
+func TestFindProviderScriptConfigDir(t *testing.T) {
+	configHome, binDir := t.TempDir(), t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	userScript := filepath.Join(configHome, "nunchux", "taskrunners", "mytool.sh")
+	writeFile(t, userScript, "")
+	writeFile(t, filepath.Join(binDir, "mytool.sh"), "")
+
+	if got := findProviderScript("mytool", binDir); got != userScript {
+		t.Errorf("findProviderScript = %q, want %q", got, userScript)
+	}
+}
+
+func TestFindTaskProviderPrecedence(t *testing.T) {
+	configHome, binDir := t.TempDir(), t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	writeFile(t, filepath.Join(binDir, "just.sh"), "")
+	writeFile(t, filepath.Join(binDir, "taskrunners", "mytool.sh"), "")
+	cfg := func(name string) config.TaskrunnerConfig { return config.TaskrunnerConfig{Name: name} }
+
+	// Shipped scripts don't replace built-in providers
+	if p, ok := FindTaskProvider(cfg("just"), binDir).(*JustProvider); !ok {
+		t.Errorf("just = %T, want the built-in provider", p)
+	}
+	if p, ok := FindTaskProvider(cfg("mytool"), binDir).(*ScriptProvider); !ok || p.path != filepath.Join(binDir, "taskrunners", "mytool.sh") {
+		t.Errorf("mytool = %+v, want the shipped script", p)
+	}
+
+	// The user's own scripts replace both
+	for _, name := range []string{"just", "mytool"} {
+		userScript := filepath.Join(configHome, "nunchux", "taskrunners", name+".sh")
+		writeFile(t, userScript, "")
+		if p, ok := FindTaskProvider(cfg(name), binDir).(*ScriptProvider); !ok || p.path != userScript {
+			t.Errorf("%s = %+v, want the user's script", name, p)
+		}
+	}
+}
+
+func TestProviderScriptProtocols(t *testing.T) {
+	binDir, dir := t.TempDir(), t.TempDir()
+	writeFile(t, filepath.Join(binDir, "v1.sh"), `plugin_items() { printf 'build\tv1 build\tBuild it\n'; }`)
+	writeFile(t, filepath.Join(binDir, "v2.sh"), `
+plugin_items() { printf 'old\tv2 old\n'; }
+plugin_items_json() {
+  echo '{"name": "build", "cmd": "make", "dir": "web", "group": "web", "icon": "🌐"}'
+  echo '{"name": "test", "cmd": "go test", "desc": "Run tests", "params": [{"name": "pkg", "default": "./...", "optional": true}, {"name": "FLAGS", "kind": "variable"}]}'
+  echo 'not json'
+  echo '{"name": "lint", "cmd": "make lint", "dir": "/srv", "group": "web"}'
+}
+`)
+
+	tasks, err := getProviderTasks(context.Background(), filepath.Join(binDir, "v1.sh"), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []TaskrunnerTask{{TaskName: "build", Cmd: "v1 build", Description: "Build it"}}; !reflect.DeepEqual(tasks, want) {
+		t.Errorf("v1 tasks = %+v", tasks)
+	}
+
+	tasks, err = getProviderTasks(context.Background(), filepath.Join(binDir, "v2.sh"), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []TaskrunnerTask{
+		{TaskName: "build", Cmd: "make", Dir: filepath.Join(dir, "web"), Group: "web", Icon: "🌐"},
+		{TaskName: "lint", Cmd: "make lint", Dir: "/srv", Group: "web"},
+		{TaskName: "test", Cmd: "go test", Description: "Run tests", Params: []TaskParam{
+			{Name: "pkg", Default: "./...", Optional: true},
+			{Name: "FLAGS", Kind: ParamVariable},
+		}},
+	}
+	if !reflect.DeepEqual(tasks, want) {
+		t.Errorf("v2 tasks = %+v", tasks)
+	}
+}
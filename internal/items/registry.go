@@ -3,11 +3,15 @@ package items
 import (
 	"context"
 	"fmt"
+	"io"
+	"path"
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"nunchux/internal/config"
+	"nunchux/internal/history"
 )
 
 // menuResult holds formatted item data for sorting
@@ -21,10 +25,17 @@ type Registry struct {
 	Items            []Item
 	TaskrunnerItems  []Item // Taskrunner items (including dividers)
 	TaskrunnerConfig []config.TaskrunnerConfig
+	TaskOverrides    []config.TaskOverride
+	Pipelines        []config.Pipeline
 	Settings         *config.Settings
 	Order            config.OrderConfig
-	Shortcuts        map[string]string         // key -> item name
-	ValidationErrors []config.ValidationError  // shortcut validation errors
+	Shortcuts        map[string]string        // key -> item name
+	ValidationErrors []config.ValidationError // shortcut validation errors
+	StatusRunner     StatusRunner             // Runs app and menu status commands
+	ConfigPath       string                   // Config file the registry was built from
+	Dir              string                   // Working directory for taskrunners (empty = tmux pane path)
+
+	taskrunnersLoaded bool
 }
 
 // NewRegistry creates a registry from config
@@ -32,7 +43,11 @@ func NewRegistry(cfg *config.Config) *Registry {
 	r := &Registry{
 		Settings:         &cfg.Settings,
 		TaskrunnerConfig: cfg.Taskrunners,
+		TaskOverrides:    cfg.Tasks,
+		Pipelines:        cfg.Pipelines,
 		Order:            cfg.Order,
+		StatusRunner:     NewStatusRunner(&cfg.Settings, ""),
+		ConfigPath:       cfg.Path,
 	}
 
 	// Validate and register shortcuts
@@ -40,13 +55,13 @@ func NewRegistry(cfg *config.Config) *Registry {
 
 	// Add all items to single slice
 	for _, app := range cfg.Apps {
-		item := &AppItem{App: app, Settings: &cfg.Settings}
+		item := &AppItem{App: app, Settings: &cfg.Settings, Runner: r.StatusRunner}
 		r.Items = append(r.Items, item)
 		validator.Register(app.Shortcut, app.Name)
 	}
 
 	for _, menu := range cfg.Menus {
-		item := &MenuItem{Menu: menu, Settings: &cfg.Settings}
+		item := &MenuItem{Menu: menu, Settings: &cfg.Settings, Runner: r.StatusRunner}
 		r.Items = append(r.Items, item)
 		validator.Register(menu.Shortcut, menu.Name)
 	}
@@ -57,46 +72,170 @@ func NewRegistry(cfg *config.Config) *Registry {
 		validator.Register(db.Shortcut, db.Name)
 	}
 
+	// Tasks are loaded later, but their shortcuts are known from the config
+	for _, o := range cfg.Tasks {
+		validator.Register(o.Shortcut, o.Runner+":"+o.Task)
+	}
+
 	r.Shortcuts = validator.Shortcuts()
 	r.ValidationErrors = validator.Errors()
 
 	return r
 }
 
-// LoadTaskrunners loads taskrunner items from provider scripts
+// Close releases resources held by the registry (the batch status co-process)
+func (r *Registry) Close() error {
+	if closer, ok := r.StatusRunner.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// SetStatusRunner replaces the status runner on the registry and its items
+func (r *Registry) SetStatusRunner(runner StatusRunner) {
+	r.StatusRunner = runner
+	for _, item := range r.Items {
+		switch it := item.(type) {
+		case *AppItem:
+			it.Runner = runner
+		case *MenuItem:
+			it.Runner = runner
+		}
+	}
+}
+
+// WorkDir returns the directory taskrunners are discovered in
+func (r *Registry) WorkDir() string {
+	if r.Dir == "" {
+		r.Dir = getPaneCurrentPath()
+	}
+	return r.Dir
+}
+
+// EnsureTaskrunners loads taskrunners unless they are already loaded
+func (r *Registry) EnsureTaskrunners(ctx context.Context) {
+	if !r.taskrunnersLoaded {
+		r.LoadTaskrunners(ctx)
+	}
+}
+
+// LoadTaskrunners loads taskrunner items from their providers, all at once.
 // This should be called once at startup or when refreshing the menu
 func (r *Registry) LoadTaskrunners(ctx context.Context) {
 	r.TaskrunnerItems = nil
+	r.taskrunnersLoaded = true
+	dir := r.WorkDir()
 
-	for _, cfg := range r.TaskrunnerConfig {
+	// Each runner's result goes in its own slot, keeping config order
+	type loaded struct {
+		tasks       []TaskrunnerTask
+		icon, label string
+	}
+	results := make([]loaded, len(r.TaskrunnerConfig))
+	var wg sync.WaitGroup
+	for i, cfg := range r.TaskrunnerConfig {
 		if !cfg.Enabled {
 			continue
 		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tasks, icon, label, err := LoadTaskrunnerTasks(ctx, cfg, r.Settings, dir)
+			if err == nil {
+				results[i] = loaded{tasks, icon, label}
+			}
+		}()
+	}
+	wg.Wait()
 
-		tasks, icon, label, err := LoadTaskrunnerTasks(ctx, cfg, r.Settings)
-		if err != nil || len(tasks) == 0 {
+	for i, cfg := range r.TaskrunnerConfig {
+		res := results[i]
+		res.tasks = filterTasks(cfg, res.tasks)
+		if len(res.tasks) == 0 {
 			continue
 		}
 
-		// Add divider for this taskrunner
-		r.TaskrunnerItems = append(r.TaskrunnerItems, &TaskrunnerDivider{
-			Runner: cfg.Name,
-			Icon:   icon,
-			Label:  label,
-		})
+		// Add divider for this taskrunner, or the entry opening its submenu
+		if taskDisplay(cfg, r.Settings) == config.DisplaySubmenu {
+			r.TaskrunnerItems = append(r.TaskrunnerItems, &TaskrunnerMenuItem{
+				Runner: cfg.Name,
+				Label:  res.label,
+				Count:  len(res.tasks),
+			})
+		} else {
+			r.TaskrunnerItems = append(r.TaskrunnerItems, &TaskrunnerDivider{
+				Runner: cfg.Name,
+				Icon:   res.icon,
+				Label:  res.label,
+			})
+		}
 
 		// Add task items
-		for _, task := range tasks {
+		for _, task := range res.tasks {
 			r.TaskrunnerItems = append(r.TaskrunnerItems, &TaskrunnerItem{
 				Runner:   cfg.Name,
 				Task:     task,
 				Config:   cfg,
 				Settings: r.Settings,
-				Icon:     icon,
-				Label:    label,
+				Icon:     res.icon,
+				Label:    res.label,
+				Override: r.taskOverride(cfg.Name, task.FullName()),
 			})
 		}
 	}
+
+	// Pipelines whose tasks are all available in this project
+	var pipelines []Item
+	for _, p := range r.Pipelines {
+		steps, err := r.resolvePipeline(p, dir)
+		if err != nil {
+			continue
+		}
+		pipelines = append(pipelines, &PipelineItem{Pipeline: p, Steps: steps, Settings: r.Settings})
+	}
+	if len(pipelines) > 0 {
+		r.TaskrunnerItems = append(r.TaskrunnerItems, &TaskrunnerDivider{Runner: "pipeline", Label: "pipelines"})
+		r.TaskrunnerItems = append(r.TaskrunnerItems, pipelines...)
+	}
+}
+
+// filterTasks keeps the tasks matching the runner's include globs and not
+// its exclude globs, up to max_items
+func filterTasks(cfg config.TaskrunnerConfig, tasks []TaskrunnerTask) []TaskrunnerTask {
+	var kept []TaskrunnerTask
+	for _, task := range tasks {
+		if cfg.MaxItems > 0 && len(kept) == cfg.MaxItems {
+			break
+		}
+		if len(cfg.Include) > 0 && !matchAny(cfg.Include, task.FullName()) {
+			continue
+		}
+		if matchAny(cfg.Exclude, task.FullName()) {
+			continue
+		}
+		kept = append(kept, task)
+	}
+	return kept
+}
+
+// matchAny reports whether name matches one of the globs
+func matchAny(globs []string, name string) bool {
+	for _, glob := range globs {
+		if ok, _ := path.Match(glob, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// taskOverride returns the [task:runner:name] settings of a task, or nil
+func (r *Registry) taskOverride(runner, task string) *config.TaskOverride {
+	for i, o := range r.TaskOverrides {
+		if o.Runner == runner && o.Task == task {
+			return &r.TaskOverrides[i]
+		}
+	}
+	return nil
 }
 
 // BuildMenu builds the menu content for fzf
@@ -125,31 +264,16 @@ func (r *Registry) BuildMenu(ctx context.Context, runningWindows map[string]bool
 			maxWidth = w
 		}
 	}
-	// Include taskrunner items in width calculation (main menu only)
-	if currentMenu == "" {
-		for _, item := range r.TaskrunnerItems {
-			if w := len(item.DisplayName()); w > maxWidth {
-				maxWidth = w
-			}
+	// Include taskrunner items in width calculation
+	taskItems := r.taskMenuItems(currentMenu)
+	for _, item := range taskItems {
+		if w := len(item.DisplayName()); w > maxWidth {
+			maxWidth = w
 		}
 	}
 
-	// Format all items in parallel
-	results := make([]menuResult, len(filtered))
-
-	var wg sync.WaitGroup
-	for i, item := range filtered {
-		wg.Add(1)
-		go func(i int, item Item) {
-			defer wg.Done()
-			isRunning := runningWindows[item.Name()]
-			results[i] = menuResult{
-				name: item.Name(),
-				line: item.FormatLine(ctx, isRunning),
-			}
-		}(i, item)
-	}
-	wg.Wait()
+	// Format all items in parallel, within the menu budget
+	results := r.formatItems(ctx, filtered, runningWindows)
 
 	// Sort by order config
 	r.sortResults(results, currentMenu)
@@ -164,13 +288,20 @@ func (r *Registry) BuildMenu(ctx context.Context, runningWindows map[string]bool
 		lines = append(lines, line)
 	}
 
-	// Add taskrunner items for main menu
-	if currentMenu == "" && len(r.TaskrunnerItems) > 0 {
-		for _, item := range r.TaskrunnerItems {
+	// Add taskrunner items
+	if len(taskItems) > 0 {
+		lastRuns := history.LastRuns(r.WorkDir())
+		for _, item := range taskItems {
 			// Check running status for taskrunner window
 			isRunning := false
-			if trItem, ok := item.(*TaskrunnerItem); ok {
-				isRunning = runningWindows[trItem.WindowName()]
+			switch it := item.(type) {
+			case *TaskrunnerItem:
+				isRunning = runningWindows[it.WindowName()]
+				it.LastRun = lastRunOf(lastRuns, it.Name())
+				it.Watched = hasWindowPrefix(runningWindows, it.WatchWindowName())
+			case *PipelineItem:
+				isRunning = runningWindows[it.WindowName()]
+				it.LastRun = lastRunOf(lastRuns, it.Name())
 			}
 			line := item.FormatLine(ctx, isRunning)
 			// Don't align dividers
@@ -187,6 +318,128 @@ func (r *Registry) BuildMenu(ctx context.Context, runningWindows map[string]bool
 	return strings.Join(lines, "\n")
 }
 
+// taskMenuItems returns the taskrunner items shown in a menu: in the main
+// menu, all but the tasks of runners in submenu mode, and in a runner's
+// submenu (tasks:<runner>), that runner's tasks
+func (r *Registry) taskMenuItems(currentMenu string) []Item {
+	runner, inRunnerMenu := strings.CutPrefix(currentMenu, TaskMenuPrefix)
+	var shown []Item
+	for _, item := range r.TaskrunnerItems {
+		tr, isTask := item.(*TaskrunnerItem)
+		switch {
+		case inRunnerMenu:
+			if isTask && tr.Runner == runner {
+				shown = append(shown, item)
+			}
+		case currentMenu == "":
+			if !isTask || !tr.InSubmenu() {
+				shown = append(shown, item)
+			}
+		}
+	}
+	return shown
+}
+
+// lastRunOf returns the last run of a task, or nil if it never ran
+func lastRunOf(lastRuns map[string]history.Run, name string) *history.Run {
+	if run, ok := lastRuns[name]; ok {
+		return &run
+	}
+	return nil
+}
+
+// hasWindowPrefix reports whether a running window's name starts with
+// prefix, as it does while a task's status icon follows it
+func hasWindowPrefix(runningWindows map[string]bool, prefix string) bool {
+	for name := range runningWindows {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// formattedLine is a line produced by a formatItems worker
+type formattedLine struct {
+	index int
+	line  string
+}
+
+// pendingLiner is implemented by items that can be shown with a pending
+// status without running anything
+type pendingLiner interface {
+	PendingLine(isRunning bool) string
+}
+
+// formatItems formats items on a bounded worker pool. If the menu budget
+// runs out first, unfinished items are shown with a pending status instead.
+// They aren't formatted again here: their worker may still be at it.
+func (r *Registry) formatItems(ctx context.Context, filtered []Item, runningWindows map[string]bool) []menuResult {
+	results := make([]menuResult, len(filtered))
+	if len(filtered) == 0 {
+		return results
+	}
+
+	if r.Settings.MenuBudgetMs > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(r.Settings.MenuBudgetMs)*time.Millisecond)
+		defer cancel()
+	}
+
+	workers := r.Settings.StatusConcurrency
+	if workers <= 0 || workers > len(filtered) {
+		workers = len(filtered)
+	}
+
+	// Buffered so workers still running after the budget never block
+	jobs := make(chan int, len(filtered))
+	done := make(chan formattedLine, len(filtered))
+	for i := range filtered {
+		jobs <- i
+	}
+	close(jobs)
+
+	for w := 0; w < workers; w++ {
+		go func() {
+			for i := range jobs {
+				if ctx.Err() != nil {
+					return
+				}
+				item := filtered[i]
+				done <- formattedLine{index: i, line: item.FormatLine(ctx, runningWindows[item.Name()])}
+			}
+		}()
+	}
+
+	finished := make([]bool, len(filtered))
+collect:
+	for remaining := len(filtered); remaining > 0; remaining-- {
+		select {
+		case res := <-done:
+			results[res.index] = menuResult{name: filtered[res.index].Name(), line: res.line}
+			finished[res.index] = true
+		case <-ctx.Done():
+			break collect
+		}
+	}
+
+	for i, item := range filtered {
+		if !finished[i] {
+			results[i] = menuResult{name: item.Name(), line: pendingLine(item, runningWindows[item.Name()])}
+		}
+	}
+
+	return results
+}
+
+// pendingLine is the menu line of an item whose status isn't ready
+func pendingLine(item Item, isRunning bool) string {
+	if p, ok := item.(pendingLiner); ok {
+		return p.PendingLine(isRunning)
+	}
+	return fmt.Sprintf("▸ %s\x00%s\t%s\t%s", item.DisplayName(), StatusPending, item.Shortcut(), item.Name())
+}
+
 // alignDisplayColumn re-aligns the display column to the specified width
 // Line format: "icon name\x00desc\t..." -> "icon name<padding>  desc\t..."
 func alignDisplayColumn(line string, maxWidth int) string {
@@ -0,0 +1,410 @@
+package items
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"nunchux/internal/config"
+	"nunchux/internal/history"
+	"nunchux/internal/shell"
+)
+
+// fakeStatusRunner answers status commands without forking. Commands named
+// "slow" block until their context is done; everything else sleeps for delay.
+type fakeStatusRunner struct {
+	delay time.Duration
+
+	mu          sync.Mutex
+	inFlight    int
+	maxInFlight int
+}
+
+func (f *fakeStatusRunner) Run(ctx context.Context, cmd string) (string, error) {
+	f.mu.Lock()
+	f.inFlight++
+	if f.inFlight > f.maxInFlight {
+		f.maxInFlight = f.inFlight
+	}
+	f.mu.Unlock()
+
+	defer func() {
+		f.mu.Lock()
+		f.inFlight--
+		f.mu.Unlock()
+	}()
+
+	if cmd == "slow" {
+		<-ctx.Done()
+		return "", ctx.Err()
+	}
+
+	select {
+	case <-time.After(f.delay):
+		return "status:" + cmd, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func newTestRegistry(settings config.Settings, statuses map[string]string) *Registry {
+	var names []string
+	for name := range statuses {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	cfg := &config.Config{Settings: settings}
+	for _, name := range names {
+		cfg.Apps = append(cfg.Apps, config.App{Name: name, Cmd: name, Status: statuses[name]})
+	}
+	return NewRegistry(cfg)
+}
+
+// menuLine returns the menu line for the named item
+func menuLine(content, name string) string {
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasSuffix(line, "\t"+name) {
+			return line
+		}
+	}
+	return ""
+}
+
+func TestBuildMenuBoundedConcurrency(t *testing.T) {
+	settings := config.DefaultSettings()
+	settings.StatusConcurrency = 3
+
+	statuses := make(map[string]string)
+	for _, name := range []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"} {
+		statuses[name] = name
+	}
+	r := newTestRegistry(settings, statuses)
+	fake := &fakeStatusRunner{delay: 20 * time.Millisecond}
+	r.SetStatusRunner(fake)
+
+	content := r.BuildMenu(context.Background(), nil, "")
+
+	if fake.maxInFlight > 3 {
+		t.Errorf("max concurrent status commands = %d, want <= 3", fake.maxInFlight)
+	}
+	for name := range statuses {
+		if line := menuLine(content, name); !strings.Contains(line, "status:"+name) {
+			t.Errorf("line for %s = %q, want status", name, line)
+		}
+	}
+}
+
+func TestBuildMenuBudget(t *testing.T) {
+	settings := config.DefaultSettings()
+	settings.MenuBudgetMs = 100
+
+	r := newTestRegistry(settings, map[string]string{
+		"fast":  "fast",
+		"slow":  "slow",
+		"plain": "",
+	})
+	r.SetStatusRunner(&fakeStatusRunner{})
+
+	start := time.Now()
+	content := r.BuildMenu(context.Background(), nil, "")
+	if elapsed := time.Since(start); elapsed > 400*time.Millisecond {
+		t.Errorf("BuildMenu took %v, want it to stop near the 100ms budget", elapsed)
+	}
+
+	if line := menuLine(content, "fast"); !strings.Contains(line, "status:fast") {
+		t.Errorf("fast item = %q, want finished status", line)
+	}
+	if line := menuLine(content, "slow"); !strings.Contains(line, StatusPending) {
+		t.Errorf("slow item = %q, want pending status", line)
+	}
+	if line := menuLine(content, "plain"); line == "" || strings.Contains(line, StatusPending) {
+		t.Errorf("plain item = %q, want rendered without status", line)
+	}
+}
+
+func TestBuildMenuBudgetStopsQueuedItems(t *testing.T) {
+	settings := config.DefaultSettings()
+	settings.MenuBudgetMs = 50
+	settings.StatusConcurrency = 1
+
+	r := newTestRegistry(settings, map[string]string{
+		"a": "slow",
+		"b": "b",
+		"c": "c",
+	})
+	fake := &fakeStatusRunner{}
+	r.SetStatusRunner(fake)
+
+	content := r.BuildMenu(context.Background(), nil, "")
+
+	// The single worker is stuck on "a"; the queued items never start
+	for _, name := range []string{"a", "b", "c"} {
+		if line := menuLine(content, name); !strings.Contains(line, StatusPending) {
+			t.Errorf("item %s = %q, want pending status", name, line)
+		}
+	}
+}
+
+func TestTaskrunnerLastRun(t *testing.T) {
+	settings := config.DefaultSettings()
+	tr := &TaskrunnerItem{Runner: "just", Task: TaskrunnerTask{TaskName: "test", Description: "Run tests"}, Settings: &settings, Label: "just"}
+
+	if line := tr.FormatLine(context.Background(), false); !strings.HasPrefix(line, settings.IconStopped+" ") {
+		t.Errorf("never run: %q", line)
+	}
+
+	tr.LastRun = &history.Run{Start: time.Now().Add(-5 * time.Minute), Duration: 1200 * time.Millisecond, ExitCode: 1}
+	line := tr.FormatLine(context.Background(), false)
+	if !strings.HasPrefix(line, settings.TaskrunnerIconFailed+" ") || !strings.Contains(line, "Run tests (exit 1 · 5m ago · 1.2s)") {
+		t.Errorf("failed run: %q", line)
+	}
+
+	line = tr.FormatLine(context.Background(), true)
+	if !strings.HasPrefix(line, settings.TaskrunnerIconRunning+" ") {
+		t.Errorf("running: %q", line)
+	}
+
+	// A watch window, whatever status its name ends with, marks the task watched
+	running := map[string]bool{"just » test 👀 ✅": true}
+	tr.Watched = hasWindowPrefix(running, tr.WatchWindowName())
+	if line = tr.FormatLine(context.Background(), false); !strings.HasPrefix(line, settings.TaskrunnerIconWatch+" ") {
+		t.Errorf("watched: %q", line)
+	}
+	if hasWindowPrefix(running, "just » lint") {
+		t.Error("other task counted as watched")
+	}
+}
+
+func TestResolvePipeline(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	settings := config.DefaultSettings()
+	r := &Registry{Settings: &settings}
+	r.TaskrunnerItems = []Item{
+		&TaskrunnerItem{Runner: "just", Task: TaskrunnerTask{TaskName: "fmt", Cmd: "just fmt"}, Settings: &settings},
+	}
+
+	steps, err := r.resolvePipeline(config.Pipeline{Name: "check", Steps: []string{"just:fmt", "cmd:go vet ./... && go test ./..."}}, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []PipelineStep{{"fmt", "just fmt"}, {"go vet ./... &&…", "go vet ./... && go test ./..."}}
+	if len(steps) != len(want) || steps[0] != want[0] || steps[1] != want[1] {
+		t.Errorf("steps = %q, want %q", steps, want)
+	}
+
+	if _, err := r.resolvePipeline(config.Pipeline{Name: "check", Steps: []string{"npm:lint"}}, ""); err == nil {
+		t.Error("a pipeline with an unavailable task should not resolve")
+	}
+}
+
+func TestTaskrunnerCache(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	binDir, dir := t.TempDir(), t.TempDir()
+	calls := filepath.Join(t.TempDir(), "calls")
+	writeFile(t, filepath.Join(binDir, "fake.sh"), `
+plugin_icon() { echo F; }
+plugin_label() { echo fake; }
+plugin_items() { echo x >> `+shell.Quote(calls)+`; printf 'build\tfake build\tBuild it\n'; }
+`)
+	justfile := filepath.Join(dir, "justfile")
+	writeFile(t, justfile, "build:\n")
+
+	settings := config.DefaultSettings()
+	settings.BinDir = binDir
+	r := &Registry{Settings: &settings, Dir: dir, TaskrunnerConfig: []config.TaskrunnerConfig{{Name: "fake", Enabled: true}}}
+	load := func() int {
+		t.Helper()
+		r.LoadTaskrunners(context.Background())
+		if r.FindTaskrunnerItem("fake:build") == nil {
+			t.Fatal("fake:build not loaded")
+		}
+		data, _ := os.ReadFile(calls)
+		return strings.Count(string(data), "x")
+	}
+
+	if n := load(); n != 1 {
+		t.Fatalf("first load ran the provider %d times", n)
+	}
+	if n := load(); n != 1 {
+		t.Errorf("cached load ran the provider again (%d)", n)
+	}
+
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(justfile, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if n := load(); n != 2 {
+		t.Errorf("load after a source file change ran the provider %d times, want 2", n)
+	}
+}
+
+func TestTaskSignatureSources(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "package.json"), `{"workspaces": ["packages/*"]}`)
+	writeFile(t, filepath.Join(root, "packages/web/package.json"), `{"name": "web"}`)
+	writeFile(t, filepath.Join(root, "Cargo.toml"), "[workspace]\nmembers = [\"crates/*\"]\n")
+	writeFile(t, filepath.Join(root, "crates/cli/Cargo.toml"), "[package]\nname = \"cli\"\n")
+	writeFile(t, filepath.Join(root, "pyproject.toml"), "[project]\n")
+
+	tests := []struct {
+		runner string
+		change string // File created or changed after the first signature
+	}{
+		{"npm", "packages/web/package.json"},
+		{"npm", "packages/api/package.json"},
+		{"npm", "yarn.lock"},
+		{"cargo", "crates/cli/Cargo.toml"},
+		{"cargo", "crates/cli/src/bin/tool.rs"},
+		{"python", "uv.lock"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.runner+" "+tt.change, func(t *testing.T) {
+			cfg := config.TaskrunnerConfig{Name: tt.runner}
+			provider := builtinProviders[tt.runner](cfg)
+			before := taskSignature(cfg, provider, root)
+			path := filepath.Join(root, tt.change)
+			writeFile(t, path, "{}")
+			later := time.Now().Add(time.Hour)
+			os.Chtimes(path, later, later)
+			os.Chtimes(filepath.Dir(path), later, later)
+			if taskSignature(cfg, provider, root) == before {
+				t.Errorf("signature unchanged after writing %s", tt.change)
+			}
+		})
+	}
+
+	if sub := filepath.Join(root, "packages/web"); taskCachePath("npm", root) == taskCachePath("npm", sub) {
+		t.Error("directories of one project share a cache file")
+	}
+}
+
+func TestTaskGroupsDuplicateNames(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	binDir := t.TempDir()
+	writeFile(t, filepath.Join(binDir, "fake.sh"), `
+plugin_items_json() {
+  echo '{"name": "build", "cmd": "make -C web", "group": "web"}'
+  echo '{"name": "build", "cmd": "make -C api", "group": "api"}'
+  echo '{"name": "build", "cmd": "make"}'
+}
+`)
+	cfg := &config.Config{
+		Settings:    config.DefaultSettings(),
+		Taskrunners: []config.TaskrunnerConfig{{Name: "fake", Enabled: true, Label: "fake"}},
+		Tasks:       []config.TaskOverride{{Runner: "fake", Task: "api › build", Confirm: true}},
+	}
+	cfg.Settings.BinDir = binDir
+	r := NewRegistry(cfg)
+	r.Dir = t.TempDir()
+	r.LoadTaskrunners(context.Background())
+
+	windows := make(map[string]bool)
+	for name, cmd := range map[string]string{"fake:web › build": "make -C web", "fake:api › build": "make -C api", "fake:build": "make"} {
+		tr := r.FindTaskrunnerItem(name)
+		if tr == nil || tr.Task.Cmd != cmd {
+			t.Fatalf("%s = %+v, want the task running %q", name, tr, cmd)
+		}
+		windows[tr.WindowName()] = true
+	}
+	if len(windows) != 3 {
+		t.Errorf("window names collide: %v", windows)
+	}
+	if !r.FindTaskrunnerItem("fake:api › build").Confirm() || r.FindTaskrunnerItem("fake:web › build").Confirm() {
+		t.Error("override not applied to the grouped task alone")
+	}
+}
+
+func TestTaskFilterAndOverrides(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	binDir := t.TempDir()
+	writeFile(t, filepath.Join(binDir, "fake.sh"), `
+plugin_items() { printf '%s\tfake %s\n' build build deploy deploy _helper _helper ci-lint ci-lint ci-test ci-test test test; }
+`)
+
+	cfg := &config.Config{
+		Settings: config.DefaultSettings(),
+		Taskrunners: []config.TaskrunnerConfig{{
+			Name: "fake", Enabled: true, Label: "fake",
+			Exclude: []string{"_*", "ci-*"}, MaxItems: 3,
+		}},
+		Tasks: []config.TaskOverride{{
+			Runner: "fake", Task: "deploy", Label: "ship", Desc: "Deploy to prod",
+			PrimaryAction: config.ActionPaneBelow, Confirm: true, Shortcut: "alt-d",
+		}},
+	}
+	cfg.Settings.BinDir = binDir
+	r := NewRegistry(cfg)
+	r.Dir = t.TempDir()
+	r.LoadTaskrunners(context.Background())
+
+	var names []string
+	for _, item := range r.TaskrunnerItems {
+		if tr, ok := item.(*TaskrunnerItem); ok {
+			names = append(names, tr.Task.TaskName)
+		}
+	}
+	if want := []string{"build", "deploy", "test"}; strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Errorf("tasks = %v, want %v", names, want)
+	}
+
+	deploy := r.FindTaskrunnerItem("fake:deploy")
+	if deploy.DisplayName() != "fake ship" || deploy.Description() != "Deploy to prod" ||
+		deploy.GetPrimaryAction() != config.ActionPaneBelow || !deploy.Confirm() || deploy.Shortcut() != "alt-d" {
+		t.Errorf("deploy override not applied: %q %q %q", deploy.DisplayName(), deploy.Description(), deploy.GetPrimaryAction())
+	}
+	if r.Shortcuts["alt-d"] != "fake:deploy" {
+		t.Errorf("shortcuts = %v", r.Shortcuts)
+	}
+	if build := r.FindTaskrunnerItem("fake:build"); build.Confirm() || build.GetPrimaryAction() != config.ActionWindow {
+		t.Error("override applied to the wrong task")
+	}
+
+	r.TaskrunnerConfig[0] = config.TaskrunnerConfig{Name: "fake", Enabled: true, Include: []string{"ci-*"}}
+	r.LoadTaskrunners(context.Background())
+	if r.FindTaskrunnerItem("fake:ci-lint") == nil || r.FindTaskrunnerItem("fake:build") != nil {
+		t.Error("include globs not applied")
+	}
+}
+
+func TestTaskrunnerSubmenu(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	binDir := t.TempDir()
+	writeFile(t, filepath.Join(binDir, "fake.sh"), `plugin_items() { printf 'build\tfake build\ntest\tfake test\n'; }`)
+	writeFile(t, filepath.Join(binDir, "other.sh"), `plugin_items() { printf 'lint\tother lint\n'; }`)
+
+	settings := config.DefaultSettings()
+	settings.BinDir = binDir
+	settings.TaskrunnerDisplay = config.DisplaySubmenu
+	r := &Registry{Settings: &settings, Dir: t.TempDir(), TaskrunnerConfig: []config.TaskrunnerConfig{
+		{Name: "fake", Enabled: true, Label: "fake"},
+		{Name: "other", Enabled: true, Label: "other", Display: config.DisplayInline},
+	}}
+	r.LoadTaskrunners(context.Background())
+
+	main := r.BuildMenu(context.Background(), nil, "")
+	if line := menuLine(main, "tasks:fake"); !strings.HasPrefix(line, "▸ fake (2 tasks)") {
+		t.Errorf("submenu entry = %q", line)
+	}
+	// Task lines end with the command, after the name
+	hasTask := func(content, name string) bool { return strings.Contains(content, "\t"+name+"\t") }
+	if hasTask(main, "fake:build") {
+		t.Error("tasks of a runner in submenu mode should not be in the main menu")
+	}
+	if !hasTask(main, "other:lint") {
+		t.Error("inline runner tasks missing from the main menu")
+	}
+
+	sub := r.BuildMenu(context.Background(), nil, "tasks:fake")
+	if !hasTask(sub, "fake:build") || !hasTask(sub, "fake:test") || hasTask(sub, "other:lint") {
+		t.Errorf("submenu = %q", sub)
+	}
+	if r.FindTaskrunnerItem("fake:build") == nil {
+		t.Error("tasks in a submenu should still be found")
+	}
+}
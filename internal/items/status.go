@@ -0,0 +1,314 @@
+package items
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"nunchux/internal/config"
+	"nunchux/internal/shell"
+)
+
+// Status modes for running status commands
+const (
+	StatusModeProcess = "process" // One bash process per command
+	StatusModeBatch   = "batch"   // All commands in one persistent bash co-process
+)
+
+// statusTimeout is the maximum time a single status command may run
+const statusTimeout = 500 * time.Millisecond
+
+// StatusPending is shown in place of a status that did not finish within
+// the menu budget
+const StatusPending = "…"
+
+// StatusRunner runs shell status commands and returns their trimmed output
+type StatusRunner interface {
+	Run(ctx context.Context, cmd string) (string, error)
+}
+
+// NewStatusRunner creates the status runner selected by settings. Commands
+// run in dir (empty = the current directory).
+func NewStatusRunner(settings *config.Settings, dir string) StatusRunner {
+	process := &ProcessStatusRunner{BinDir: settings.BinDir, Dir: dir}
+	if settings.StatusMode == StatusModeBatch {
+		return NewBatchStatusRunner(settings.BinDir, dir, process)
+	}
+	return process
+}
+
+// statusEnv returns the environment for status commands, with the bin
+// directory on PATH for helper scripts (lines, ago, nearest)
+func statusEnv(binDir string) []string {
+	if binDir == "" {
+		return nil
+	}
+	return append(os.Environ(), "PATH="+binDir+":"+os.Getenv("PATH"))
+}
+
+// ProcessStatusRunner runs each status command in its own bash process
+type ProcessStatusRunner struct {
+	BinDir string
+	Dir    string // Working directory (empty = current directory)
+}
+
+func (p *ProcessStatusRunner) Run(ctx context.Context, statusCmd string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, statusTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "bash", "-c", statusCmd)
+	cmd.Env = statusEnv(p.BinDir)
+	cmd.Dir = p.Dir
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// errProtocol is returned when the batch co-process misbehaves
+var errProtocol = errors.New("status co-process protocol error")
+
+// BatchStatusRunner runs status commands in a single persistent bash
+// co-process. Commands are sent on stdin and their output is read back up
+// to a per-process marker line carrying the exit code. Commands are run one
+// at a time; concurrent callers queue for the co-process. A command's
+// timeout starts when it is called, not when its turn comes, so commands
+// queued behind a slow one give up with it instead of waiting in turn.
+//
+// A command that exceeds its timeout while running kills the co-process,
+// which is restarted on the next call. Any other protocol error (the co-process
+// failing to start, exiting, or writing garbage) permanently switches the
+// runner to the fallback.
+type BatchStatusRunner struct {
+	binDir   string
+	dir      string
+	fallback StatusRunner
+
+	turn   chan struct{} // Held while using the co-process
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	lines  chan string
+	marker string
+	broken bool
+}
+
+// NewBatchStatusRunner creates a batch runner that runs commands in dir and
+// uses fallback after a protocol error
+func NewBatchStatusRunner(binDir, dir string, fallback StatusRunner) *BatchStatusRunner {
+	return &BatchStatusRunner{binDir: binDir, dir: dir, fallback: fallback, turn: make(chan struct{}, 1)}
+}
+
+func (b *BatchStatusRunner) Run(ctx context.Context, statusCmd string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, statusTimeout)
+	defer cancel()
+	select {
+	case b.turn <- struct{}{}:
+		defer func() { <-b.turn }()
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+
+	if b.broken {
+		return b.fallback.Run(ctx, statusCmd)
+	}
+
+	if b.cmd == nil {
+		if err := b.start(); err != nil {
+			b.broken = true
+			return b.fallback.Run(ctx, statusCmd)
+		}
+	}
+
+	output, err := b.exec(ctx, statusCmd)
+	if errors.Is(err, errProtocol) {
+		b.stop()
+		b.broken = true
+		return b.fallback.Run(ctx, statusCmd)
+	}
+	return output, err
+}
+
+// Close stops the co-process
+func (b *BatchStatusRunner) Close() error {
+	b.turn <- struct{}{}
+	defer func() { <-b.turn }()
+	b.stop()
+	return nil
+}
+
+// start launches the bash co-process
+func (b *BatchStatusRunner) start() error {
+	nonce := make([]byte, 8)
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	cmd := exec.Command("bash", "--noprofile", "--norc", "-s")
+	cmd.Env = statusEnv(b.binDir)
+	cmd.Dir = b.dir
+	// Own process group, so stop() also kills a hung command's children
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	lines := make(chan string, 64)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(stdout)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	b.cmd = cmd
+	b.stdin = stdin
+	b.lines = lines
+	b.marker = "__nunchux_status_" + hex.EncodeToString(nonce) + "__"
+	return nil
+}
+
+// stop kills the co-process and resets state so the next call restarts it
+func (b *BatchStatusRunner) stop() {
+	if b.cmd == nil {
+		return
+	}
+	b.stdin.Close()
+	syscall.Kill(-b.cmd.Process.Pid, syscall.SIGKILL)
+	b.cmd.Wait()
+	b.cmd = nil
+	b.stdin = nil
+	b.lines = nil
+}
+
+// exec sends one command to the co-process and collects its output, until
+// ctx is done
+func (b *BatchStatusRunner) exec(ctx context.Context, statusCmd string) (string, error) {
+	// eval keeps syntax errors from aborting the co-process, and the subshell
+	// keeps commands from leaking state (cd, variables, exit) into each other
+	request := fmt.Sprintf("( eval %s ) </dev/null 2>/dev/null; printf '\\n%s %%d\\n' \"$?\"\n",
+		shell.Quote(statusCmd), b.marker)
+	if _, err := io.WriteString(b.stdin, request); err != nil {
+		return "", fmt.Errorf("%w: %v", errProtocol, err)
+	}
+
+	var output []string
+	for {
+		select {
+		case line, ok := <-b.lines:
+			if !ok {
+				return "", fmt.Errorf("%w: co-process exited", errProtocol)
+			}
+			if !strings.HasPrefix(line, b.marker+" ") {
+				output = append(output, line)
+				continue
+			}
+			code, err := strconv.Atoi(strings.TrimPrefix(line, b.marker+" "))
+			if err != nil {
+				return "", fmt.Errorf("%w: bad marker %q", errProtocol, line)
+			}
+			if code != 0 {
+				return "", fmt.Errorf("status command exited with code %d", code)
+			}
+			return strings.TrimSpace(strings.Join(output, "\n")), nil
+
+		case <-ctx.Done():
+			// The co-process is stuck on this command; restart it next time
+			b.stop()
+			return "", ctx.Err()
+		}
+	}
+}
+
+// CachedStatusRunner remembers status output for a TTL. Once an entry is
+// stale, the old output is returned immediately while a refresh runs in the
+// background, so a long-running process (the daemon) never waits on a
+// status command it has seen before.
+type CachedStatusRunner struct {
+	runner StatusRunner
+	ttl    time.Duration
+	now    func() time.Time // Clock, replaced in tests
+
+	mu      sync.Mutex
+	entries map[string]*cachedStatus
+}
+
+type cachedStatus struct {
+	output     string
+	err        error
+	at         time.Time
+	refreshing bool
+}
+
+// NewCachedStatusRunner wraps runner with a cache of the given TTL
+func NewCachedStatusRunner(runner StatusRunner, ttl time.Duration) *CachedStatusRunner {
+	return &CachedStatusRunner{
+		runner:  runner,
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]*cachedStatus),
+	}
+}
+
+func (c *CachedStatusRunner) Run(ctx context.Context, statusCmd string) (string, error) {
+	c.mu.Lock()
+	if e, ok := c.entries[statusCmd]; ok {
+		if c.now().Sub(e.at) > c.ttl && !e.refreshing {
+			e.refreshing = true
+			go c.refresh(statusCmd)
+		}
+		c.mu.Unlock()
+		return e.output, e.err
+	}
+	c.mu.Unlock()
+
+	output, err := c.runner.Run(ctx, statusCmd)
+	if ctx.Err() == nil {
+		c.store(statusCmd, output, err)
+	}
+	return output, err
+}
+
+// refresh re-runs a stale command in the background
+func (c *CachedStatusRunner) refresh(statusCmd string) {
+	output, err := c.runner.Run(context.Background(), statusCmd)
+	c.store(statusCmd, output, err)
+}
+
+func (c *CachedStatusRunner) store(statusCmd, output string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[statusCmd] = &cachedStatus{output: output, err: err, at: c.now()}
+}
+
+// Close closes the wrapped runner
+func (c *CachedStatusRunner) Close() error {
+	if closer, ok := c.runner.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+package items
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBatchStatusRunner(t *testing.T) {
+	runner := NewBatchStatusRunner("", "", &ProcessStatusRunner{})
+	defer runner.Close()
+	ctx := context.Background()
+
+	tests := []struct {
+		cmd     string
+		want    string
+		wantErr bool
+	}{
+		{"echo hello", "hello", false},
+		{"printf 'no newline'", "no newline", false},
+		{"echo one; echo two", "one\ntwo", false},
+		{"echo it's; false", "", true},
+		{"cd /; x=1; exit 3", "", true},
+		{"echo $x $PWD", "", false}, // state from previous command must not leak
+		{"if then", "", true},       // syntax error must not kill the co-process
+		{"echo still alive", "still alive", false},
+	}
+
+	for _, tt := range tests {
+		got, err := runner.Run(ctx, tt.cmd)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Run(%q) error = %v, wantErr %v", tt.cmd, err, tt.wantErr)
+		}
+		if tt.cmd == "echo $x $PWD" {
+			if got == "1 /" {
+				t.Errorf("Run(%q) = %q, state leaked between commands", tt.cmd, got)
+			}
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Run(%q) = %q, want %q", tt.cmd, got, tt.want)
+		}
+	}
+
+	if runner.broken {
+		t.Error("runner fell back to processes without a protocol error")
+	}
+}
+
+func TestBatchStatusRunnerTimeout(t *testing.T) {
+	runner := NewBatchStatusRunner("", "", &ProcessStatusRunner{})
+	defer runner.Close()
+	ctx := context.Background()
+
+	if _, err := runner.Run(ctx, "sleep 5"); err == nil {
+		t.Fatal("expected timeout error")
+	}
+
+	// The co-process is restarted for the next command
+	got, err := runner.Run(ctx, "echo after")
+	if err != nil || got != "after" {
+		t.Errorf("Run after timeout = %q, %v; want %q", got, err, "after")
+	}
+}
+
+func TestBatchStatusRunnerQueueTimeout(t *testing.T) {
+	runner := NewBatchStatusRunner("", "", &ProcessStatusRunner{})
+	defer runner.Close()
+
+	// Timeouts run from the call, so slow commands don't add up
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := runner.Run(context.Background(), "sleep 5"); err == nil {
+				t.Error("expected timeout error")
+			}
+		}()
+	}
+	wg.Wait()
+	if elapsed := time.Since(start); elapsed > 2*statusTimeout {
+		t.Errorf("4 queued slow commands took %v, want about %v", elapsed, statusTimeout)
+	}
+}
+
+func TestBatchStatusRunnerFallback(t *testing.T) {
+	runner := NewBatchStatusRunner("", "", &ProcessStatusRunner{})
+	defer runner.Close()
+	ctx := context.Background()
+
+	// The co-process dying mid-command is a protocol error
+	got, err := runner.Run(ctx, "kill -9 $$")
+	if !runner.broken {
+		t.Fatalf("expected fallback after protocol error, got %q, %v", got, err)
+	}
+
+	got, err = runner.Run(ctx, "echo fallback")
+	if err != nil || got != "fallback" {
+		t.Errorf("Run after fallback = %q, %v; want %q", got, err, "fallback")
+	}
+}
+
+// benchmarkStatus runs 40 status commands, like a menu with 40 apps
+func benchmarkStatus(b *testing.B, runner StatusRunner) {
+	ctx := context.Background()
+	for i := 0; i < b.N; i++ {
+		for j := 0; j < 40; j++ {
+			if _, err := runner.Run(ctx, fmt.Sprintf("echo %d", j)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkStatusProcess(b *testing.B) {
+	benchmarkStatus(b, &ProcessStatusRunner{})
+}
+
+func BenchmarkStatusBatch(b *testing.B) {
+	runner := NewBatchStatusRunner("", "", &ProcessStatusRunner{})
+	defer runner.Close()
+	benchmarkStatus(b, runner)
+}
+
+// countingRunner returns how many times it has run, and signals each run
+type countingRunner struct {
+	mu   sync.Mutex
+	runs int
+	ran  chan struct{}
+}
+
+func (c *countingRunner) Run(ctx context.Context, cmd string) (string, error) {
+	c.mu.Lock()
+	c.runs++
+	n := c.runs
+	c.mu.Unlock()
+	c.ran <- struct{}{}
+	return fmt.Sprint(n), nil
+}
+
+func TestCachedStatusRunner(t *testing.T) {
+	runner := &countingRunner{ran: make(chan struct{}, 4)}
+	cached := NewCachedStatusRunner(runner, time.Minute)
+	now := time.Unix(1000, 0)
+	var clockMu sync.Mutex
+	cached.now = func() time.Time {
+		clockMu.Lock()
+		defer clockMu.Unlock()
+		return now
+	}
+	advance := func(d time.Duration) {
+		clockMu.Lock()
+		now = now.Add(d)
+		clockMu.Unlock()
+	}
+	ctx := context.Background()
+
+	if got, _ := cached.Run(ctx, "cmd"); got != "1" {
+		t.Fatalf("first run = %q, want 1", got)
+	}
+	<-runner.ran
+
+	advance(30 * time.Second)
+	if got, _ := cached.Run(ctx, "cmd"); got != "1" {
+		t.Errorf("fresh entry = %q, want the cached 1", got)
+	}
+
+	// A stale entry is returned at once, and refreshed in the background
+	advance(time.Minute)
+	if got, _ := cached.Run(ctx, "cmd"); got != "1" {
+		t.Errorf("stale entry = %q, want the cached 1", got)
+	}
+	select {
+	case <-runner.ran:
+	case <-time.After(time.Second):
+		t.Fatal("stale entry was not refreshed")
+	}
+	// The refresh stores its output right after running; wait for it
+	for deadline := time.Now().Add(time.Second); ; {
+		if got, _ := cached.Run(ctx, "cmd"); got == "2" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("refreshed output never returned")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	select {
+	case <-runner.ran:
+		t.Error("a fresh entry was refreshed again")
+	default:
+	}
+
+	// A cancelled first run is not cached
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	cached.Run(cancelled, "other")
+	<-runner.ran
+	cached.Run(ctx, "other")
+	<-runner.ran
+}
@@ -0,0 +1,61 @@
+package items
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"nunchux/internal/config"
+)
+
+// Remembered task arguments, so a prompt starts with the values used last
+// time. Stored as JSON in the cache dir, keyed by project dir and task.
+
+var taskArgsMu sync.Mutex
+
+func taskArgsPath() string {
+	return filepath.Join(config.CacheDir(), "task-args.json")
+}
+
+func taskArgsKey(dir, task string) string {
+	return dir + "\t" + task
+}
+
+func readTaskArgs() map[string][]string {
+	all := make(map[string][]string)
+	if data, err := os.ReadFile(taskArgsPath()); err == nil {
+		json.Unmarshal(data, &all)
+	}
+	return all
+}
+
+// LoadTaskArgs returns the values last used for a task in dir
+func LoadTaskArgs(dir, task string) []string {
+	taskArgsMu.Lock()
+	defer taskArgsMu.Unlock()
+	return readTaskArgs()[taskArgsKey(dir, task)]
+}
+
+// SaveTaskArgs remembers the values used for a task in dir
+func SaveTaskArgs(dir, task string, values []string) error {
+	taskArgsMu.Lock()
+	defer taskArgsMu.Unlock()
+
+	all := readTaskArgs()
+	all[taskArgsKey(dir, task)] = values
+
+	data, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(taskArgsPath()), 0755); err != nil {
+		return err
+	}
+	// Write and rename, so a concurrent reader never sees a partial file
+	tmp := taskArgsPath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, taskArgsPath())
+}
@@ -0,0 +1,137 @@
+package items
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"nunchux/internal/config"
+)
+
+// providerSourceFiles are the files each built-in provider reads its tasks
+// from, in dir or an ancestor. Other runners (scripts and list_cmd) use all
+// taskrunnerSourceFiles, unless their config lists its own sources.
+var providerSourceFiles = map[string][]string{
+	"just":    {"justfile", "Justfile", ".justfile"},
+	"npm":     {"package.json", "pnpm-workspace.yaml", "pnpm-lock.yaml", "yarn.lock", "bun.lockb", "bun.lock", "package-lock.json"},
+	"task":    taskfileNames,
+	"make":    makefileNames,
+	"cargo":   {"Cargo.toml"},
+	"go":      {"go.mod"},
+	"python":  {"pyproject.toml", "poetry.lock", "pdm.lock", "uv.lock"},
+	"compose": composeFileNames,
+}
+
+// sourceLister is implemented by providers that also read files found
+// through their project file, such as workspace members
+type sourceLister interface {
+	// SourceFiles returns the other files and directories the task list
+	// for dir is read from. A directory changes when entries are added to
+	// or removed from it.
+	SourceFiles(dir string) []string
+}
+
+// taskCacheEntry is a cached task list of one runner in one project
+type taskCacheEntry struct {
+	Signature string           `json:"signature"`
+	Icon      string           `json:"icon"`
+	Label     string           `json:"label"`
+	Tasks     []TaskrunnerTask `json:"tasks"`
+}
+
+// taskCachePath returns the cache file for a runner in dir. Directories of
+// one project get files of their own, since their task lists can differ.
+func taskCachePath(runner, dir string) string {
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	sum := sha1.Sum([]byte(runner + "\x00" + dir))
+	return filepath.Join(config.CacheDir(), "taskrunners", runner+"-"+hex.EncodeToString(sum[:4])+".json")
+}
+
+// taskSignature returns a string that changes whenever the runner's config
+// or one of its source files changes
+func taskSignature(cfg config.TaskrunnerConfig, provider TaskProvider, dir string) string {
+	var sig strings.Builder
+	fmt.Fprintf(&sig, "%s|%s|%s|%v;", cfg.Name, cfg.Icon, cfg.Label, cfg.Options)
+
+	names, ok := providerSourceFiles[cfg.Name]
+	if sources := cfg.Options["sources"]; sources != "" {
+		names = nil
+		for _, name := range strings.Split(sources, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, name)
+			}
+		}
+	} else if !ok || cfg.Options["list_cmd"] != "" {
+		names = taskrunnerSourceFiles
+	}
+	if script, ok := provider.(*ScriptProvider); ok {
+		if info, err := os.Stat(script.path); err == nil {
+			fmt.Fprintf(&sig, "%s:%d:%d;", script.path, info.Size(), info.ModTime().UnixNano())
+		}
+	}
+	if lister, ok := provider.(sourceLister); ok && cfg.Options["sources"] == "" {
+		for _, path := range lister.SourceFiles(dir) {
+			if info, err := os.Stat(path); err == nil {
+				fmt.Fprintf(&sig, "%s:%d:%d;", path, info.Size(), info.ModTime().UnixNano())
+			}
+		}
+	}
+
+	for d := dir; ; d = filepath.Dir(d) {
+		for _, name := range names {
+			if info, err := os.Stat(filepath.Join(d, name)); err == nil {
+				fmt.Fprintf(&sig, "%s/%s:%d:%d;", d, name, info.Size(), info.ModTime().UnixNano())
+			}
+		}
+		if parent := filepath.Dir(d); parent == d {
+			break
+		}
+	}
+	return sig.String()
+}
+
+// loadCachedTasks returns the cached task list of a runner if its signature
+// still matches
+func loadCachedTasks(runner, dir, signature string) (taskCacheEntry, bool) {
+	data, err := os.ReadFile(taskCachePath(runner, dir))
+	if err != nil {
+		return taskCacheEntry{}, false
+	}
+	var entry taskCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Signature != signature {
+		return taskCacheEntry{}, false
+	}
+	return entry, true
+}
+
+// saveCachedTasks stores the task list of a runner
+func saveCachedTasks(runner, dir string, entry taskCacheEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	path := taskCachePath(runner, dir)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	// Write and rename, so a concurrent reader never sees a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
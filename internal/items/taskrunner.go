@@ -2,6 +2,7 @@ package items
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
@@ -10,6 +11,8 @@ import (
 	"time"
 
 	"nunchux/internal/config"
+	"nunchux/internal/history"
+	"nunchux/internal/shell"
 )
 
 // TaskrunnerTask represents a single task from a taskrunner
@@ -17,6 +20,89 @@ type TaskrunnerTask struct {
 	TaskName    string // e.g., "build"
 	Cmd         string // e.g., "just build"
 	Description string
+	Params      []TaskParam // Arguments prompted for before running
+	Dir         string      // Directory to run in (empty = the current directory)
+	Group       string      // Shown before the name; tasks of a group are listed together
+	Icon        string      // Shown while the task has no run (empty = icon_stopped)
+}
+
+// FullName returns the task's name within its runner: the name, after the
+// group if it has one, so tasks of the same name in two groups differ
+func (t TaskrunnerTask) FullName() string {
+	if t.Group != "" {
+		return t.Group + " › " + t.TaskName
+	}
+	return t.TaskName
+}
+
+// ParamKind is how a parameter's value is passed to the task command
+type ParamKind int
+
+const (
+	ParamPositional ParamKind = iota // Appended as a quoted argument
+	ParamVariable                    // Appended as NAME=value
+	ParamRest                        // Appended verbatim, may be several words
+)
+
+// TaskParam describes an argument a task takes
+type TaskParam struct {
+	Name      string
+	Default   string
+	Optional  bool
+	Kind      ParamKind
+	Separator string // Inserted before a rest value, e.g. "--" for npm
+}
+
+// Command returns the task command with parameter values appended. Values
+// match Params by index; empty values of optional parameters are left out.
+// An empty optional positional parameter followed by other arguments gets
+// its default, since they would shift into its place; without a default,
+// that is an error.
+func (t TaskrunnerTask) Command(values []string) (string, error) {
+	cmd := t.Cmd
+	for i, param := range t.Params {
+		value := ""
+		if i < len(values) {
+			value = values[i]
+		}
+		if value == "" && param.Optional {
+			if param.Kind != ParamPositional {
+				continue
+			}
+			if !t.shiftsLater(values, i) {
+				continue
+			}
+			if param.Default == "" {
+				return "", fmt.Errorf("%s has no default and is empty, but a later parameter is set", param.Name)
+			}
+			value = param.Default
+		}
+
+		switch param.Kind {
+		case ParamPositional:
+			cmd += " " + shell.Quote(value)
+		case ParamVariable:
+			cmd += " " + param.Name + "=" + shell.Quote(value)
+		case ParamRest:
+			if param.Separator != "" {
+				cmd += " " + param.Separator
+			}
+			cmd += " " + value
+		}
+	}
+	return cmd, nil
+}
+
+// shiftsLater reports whether leaving out parameter i would move a later
+// value into its place: whether a later positional or rest value is set.
+// NAME=value variables do not depend on their position.
+func (t TaskrunnerTask) shiftsLater(values []string, i int) bool {
+	for j := i + 1; j < len(t.Params) && j < len(values); j++ {
+		if values[j] != "" && t.Params[j].Kind != ParamVariable {
+			return true
+		}
+	}
+	return false
 }
 
 // TaskrunnerItem represents a taskrunner task as a menu item
@@ -27,13 +113,16 @@ type TaskrunnerItem struct {
 	Settings *config.Settings
 	Icon     string
 	Label    string
+	Override *config.TaskOverride // [task:runner:name] settings (nil = none)
+	LastRun  *history.Run         // Last run in the current project (nil = never run)
+	Watched  bool                 // A watch window for the task is open
 }
 
 // Ensure TaskrunnerItem implements Item
 var _ Item = (*TaskrunnerItem)(nil)
 
 func (t *TaskrunnerItem) Name() string {
-	return t.Runner + ":" + t.Task.TaskName
+	return t.Runner + ":" + t.Task.FullName()
 }
 
 func (t *TaskrunnerItem) Type() ItemType {
@@ -41,37 +130,88 @@ func (t *TaskrunnerItem) Type() ItemType {
 }
 
 func (t *TaskrunnerItem) Shortcut() string {
-	return "" // Taskrunner items don't have shortcuts
+	if t.Override != nil {
+		return t.Override.Shortcut
+	}
+	return ""
 }
 
 func (t *TaskrunnerItem) Parent() string {
 	return "" // Taskrunners are always top-level
 }
 
-// DisplayName returns the formatted display name (label + task)
+// DisplayName returns the formatted display name (label + group + task)
 func (t *TaskrunnerItem) DisplayName() string {
-	return t.Label + " " + t.Task.TaskName
+	name := t.Task.TaskName
+	if t.Override != nil && t.Override.Label != "" {
+		name = t.Override.Label
+	}
+	if t.Task.Group != "" {
+		return t.Label + " " + t.Task.Group + " › " + name
+	}
+	return t.Label + " " + name
+}
+
+// Description returns the task's description, or the one from its config
+func (t *TaskrunnerItem) Description() string {
+	if t.Override != nil && t.Override.Desc != "" {
+		return t.Override.Desc
+	}
+	return t.Task.Description
+}
+
+// Confirm reports whether to ask before running the task
+func (t *TaskrunnerItem) Confirm() bool {
+	return t.Override != nil && t.Override.Confirm
 }
 
 func (t *TaskrunnerItem) FormatLine(ctx context.Context, isRunning bool) string {
-	icon := t.Settings.IconStopped
+	return formatTaskLine(t.Settings, t.Task.Icon, t.DisplayName(), t.Description(), t.Shortcut(), t.Name(), t.Task.Cmd, t.LastRun, isRunning, t.Watched)
+}
+
+// formatTaskLine formats a task-like item, with the result of its last run
+// in the icon and description. icon is shown before the first run (empty =
+// icon_stopped); a watched task shows the watch icon until it runs again.
+func formatTaskLine(settings *config.Settings, icon, displayName, desc, shortcut, name, cmd string, run *history.Run, isRunning, watched bool) string {
+	if icon == "" {
+		icon = settings.IconStopped
+	}
+	if run != nil {
+		if run.Succeeded() {
+			icon = settings.TaskrunnerIconSuccess
+		} else {
+			icon = settings.TaskrunnerIconFailed
+		}
+		status := history.Ago(run.Start) + " · " + history.FormatDuration(run.Duration)
+		if !run.Succeeded() {
+			status = fmt.Sprintf("exit %d · %s", run.ExitCode, status)
+		}
+		desc = strings.TrimSpace(desc + " (" + status + ")")
+	}
+	if watched {
+		icon = settings.TaskrunnerIconWatch
+	}
 	if isRunning {
-		icon = t.Settings.TaskrunnerIconRunning
+		icon = settings.TaskrunnerIconRunning
 	}
 
 	// Use \x00 as separator between name and desc for reliable parsing
-	display := fmt.Sprintf("%s %s\x00%s", icon, t.DisplayName(), t.Task.Description)
+	display := fmt.Sprintf("%s %s\x00%s", icon, displayName, desc)
 
 	// Format: display\tshortcut\tname\tcmd
-	return fmt.Sprintf("%s\t\t%s\t%s",
+	return fmt.Sprintf("%s\t%s\t%s\t%s",
 		display,
-		t.Name(),
-		t.Task.Cmd,
+		shortcut,
+		name,
+		cmd,
 	)
 }
 
 // GetPrimaryAction returns the action for this taskrunner
 func (t *TaskrunnerItem) GetPrimaryAction() config.Action {
+	if t.Override != nil && t.Override.PrimaryAction != "" {
+		return t.Override.PrimaryAction
+	}
 	if t.Config.PrimaryAction != "" {
 		return t.Config.PrimaryAction
 	}
@@ -81,6 +221,9 @@ func (t *TaskrunnerItem) GetPrimaryAction() config.Action {
 
 // GetSecondaryAction returns the secondary action for this taskrunner
 func (t *TaskrunnerItem) GetSecondaryAction() config.Action {
+	if t.Override != nil && t.Override.SecondaryAction != "" {
+		return t.Override.SecondaryAction
+	}
 	if t.Config.SecondaryAction != "" {
 		return t.Config.SecondaryAction
 	}
@@ -89,7 +232,79 @@ func (t *TaskrunnerItem) GetSecondaryAction() config.Action {
 
 // WindowName returns the window name for tmux
 func (t *TaskrunnerItem) WindowName() string {
-	return t.Runner + " » " + t.Task.TaskName
+	return t.Runner + " » " + t.Task.FullName()
+}
+
+// WatchWindowName is the window name of the watched task: its window name
+// with the watch icon. The status of the latest run follows it.
+func (t *TaskrunnerItem) WatchWindowName() string {
+	return t.WindowName() + " " + t.Settings.TaskrunnerIconWatch
+}
+
+// TaskMenuPrefix starts the name of a runner's task submenu, e.g. "tasks:just"
+const TaskMenuPrefix = "tasks:"
+
+// InSubmenu reports whether the task is listed in its runner's submenu
+// instead of the main menu
+func (t *TaskrunnerItem) InSubmenu() bool {
+	return taskDisplay(t.Config, t.Settings) == config.DisplaySubmenu
+}
+
+// taskDisplay returns the display mode of a runner
+func taskDisplay(cfg config.TaskrunnerConfig, settings *config.Settings) string {
+	if cfg.Display != "" {
+		return cfg.Display
+	}
+	return settings.TaskrunnerDisplay
+}
+
+// TaskrunnerMenuItem is the main menu entry that opens a runner's tasks,
+// shown instead of the divider in submenu mode
+type TaskrunnerMenuItem struct {
+	Runner string
+	Label  string
+	Count  int // Number of tasks
+}
+
+// Ensure TaskrunnerMenuItem implements Item
+var _ Item = (*TaskrunnerMenuItem)(nil)
+
+func (m *TaskrunnerMenuItem) Name() string {
+	return TaskMenuPrefix + m.Runner
+}
+
+func (m *TaskrunnerMenuItem) Type() ItemType {
+	return TypeMenu
+}
+
+func (m *TaskrunnerMenuItem) Shortcut() string {
+	return ""
+}
+
+func (m *TaskrunnerMenuItem) Parent() string {
+	return ""
+}
+
+func (m *TaskrunnerMenuItem) DisplayName() string {
+	if m.Count == 1 {
+		return m.Label + " (1 task)"
+	}
+	return fmt.Sprintf("%s (%d tasks)", m.Label, m.Count)
+}
+
+func (m *TaskrunnerMenuItem) FormatLine(ctx context.Context, isRunning bool) string {
+	// Use \x00 as separator between name and desc for reliable parsing
+	return fmt.Sprintf("▸ %s\x00\t\t%s", m.DisplayName(), m.Name())
+}
+
+// GetPrimaryAction returns empty - the entry opens a submenu
+func (m *TaskrunnerMenuItem) GetPrimaryAction() config.Action {
+	return ""
+}
+
+// GetSecondaryAction returns empty - the entry opens a submenu
+func (m *TaskrunnerMenuItem) GetSecondaryAction() config.Action {
+	return ""
 }
 
 // TaskrunnerDivider represents a divider line in the menu
@@ -156,45 +371,53 @@ func (d *TaskrunnerDivider) GetSecondaryAction() config.Action {
 	return ""
 }
 
-// LoadTaskrunnerTasks loads tasks from a taskrunner provider script
-func LoadTaskrunnerTasks(ctx context.Context, cfg config.TaskrunnerConfig, settings *config.Settings) ([]TaskrunnerTask, string, string, error) {
-	// Find the provider script
-	scriptPath := findProviderScript(cfg.Name, settings.BinDir)
-	if scriptPath == "" {
-		return nil, "", "", fmt.Errorf("taskrunner provider script not found: %s", cfg.Name)
+// LoadTaskrunnerTasks loads tasks from a taskrunner's provider, run in dir
+func LoadTaskrunnerTasks(ctx context.Context, cfg config.TaskrunnerConfig, settings *config.Settings, dir string) ([]TaskrunnerTask, string, string, error) {
+	provider := FindTaskProvider(cfg, settings.BinDir)
+	if provider == nil {
+		return nil, "", "", fmt.Errorf("taskrunner provider not found: %s", cfg.Name)
 	}
 
-	// Get icon and label from provider (or use config overrides)
+	// Repeat loads are served from the cache until a source file changes
+	signature := taskSignature(cfg, provider, dir)
+	if entry, ok := loadCachedTasks(cfg.Name, dir, signature); ok {
+		return entry.Tasks, entry.Icon, entry.Label, nil
+	}
+
+	// Icon and label from config, or the provider's defaults
 	icon := cfg.Icon
 	label := cfg.Label
 
 	if icon == "" {
-		icon = getProviderValue(ctx, scriptPath, "plugin_icon")
+		icon = provider.Icon()
 	}
 	if label == "" || label == cfg.Name {
-		providerLabel := getProviderValue(ctx, scriptPath, "plugin_label")
-		if providerLabel != "" {
+		if providerLabel := provider.Label(); providerLabel != "" {
 			label = providerLabel
 		}
 	}
 
-	// Get tasks from provider
-	tasks, err := getProviderTasks(ctx, scriptPath)
+	tasks, err := provider.Tasks(ctx, dir)
 	if err != nil {
 		return nil, icon, label, err
 	}
 
+	// Empty lists aren't cached: the tool may just not be installed yet
+	if len(tasks) > 0 {
+		saveCachedTasks(cfg.Name, dir, taskCacheEntry{Signature: signature, Icon: icon, Label: label, Tasks: tasks})
+	}
 	return tasks, icon, label, nil
 }
 
 // findProviderScript looks for a taskrunner provider script
 func findProviderScript(name string, binDir string) string {
 	// Check locations in order:
-	// 1. BinDir (same as nunchux binary)
-	// 2. BinDir/taskrunners
-	// 3. Home directory locations
+	// 1. The config directory (~/.config/nunchux/taskrunners)
+	// 2. BinDir (same as nunchux binary)
+	// 3. BinDir/taskrunners
+	// 4. Home directory locations
 
-	locations := []string{}
+	locations := []string{userProviderScriptPath(name)}
 
 	if binDir != "" {
 		locations = append(locations,
@@ -220,6 +443,55 @@ func findProviderScript(name string, binDir string) string {
 	return ""
 }
 
+// userProviderScriptPath is where the user's own provider script for a
+// taskrunner goes
+func userProviderScriptPath(name string) string {
+	return filepath.Join(config.ConfigDir(), "taskrunners", name+".sh")
+}
+
+// userProviderScript returns the user's provider script for a taskrunner,
+// or "" if there is none
+func userProviderScript(name string) string {
+	path := userProviderScriptPath(name)
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	return path
+}
+
+// taskrunnerSourceFiles are the files taskrunner providers read their tasks
+// from. A change to any of them in a directory or its ancestors means the
+// task list may have changed.
+var taskrunnerSourceFiles = append([]string{
+	"justfile", "Justfile", ".justfile",
+	"package.json", "pnpm-workspace.yaml",
+	"GNUmakefile", "makefile", "Makefile",
+	"Cargo.toml", "go.mod", "pyproject.toml",
+	"compose.yaml", "compose.yml", "docker-compose.yaml", "docker-compose.yml",
+}, taskfileNames...)
+
+// TaskrunnerSignature returns a string that changes whenever a taskrunner
+// source file in dir or one of its ancestors is created, removed or modified
+func TaskrunnerSignature(dir string) string {
+	var sig strings.Builder
+	for d := dir; ; d = filepath.Dir(d) {
+		for _, name := range taskrunnerSourceFiles {
+			if info, err := os.Stat(filepath.Join(d, name)); err == nil {
+				fmt.Fprintf(&sig, "%s/%s:%d:%d;", d, name, info.Size(), info.ModTime().UnixNano())
+			}
+		}
+		if parent := filepath.Dir(d); parent == d {
+			break
+		}
+	}
+	return sig.String()
+}
+
+// PaneCurrentPath returns the tmux pane's current working directory
+func PaneCurrentPath() string {
+	return getPaneCurrentPath()
+}
+
 // getPaneCurrentPath returns the tmux pane's current working directory
 func getPaneCurrentPath() string {
 	// Allow override via environment (useful for testing)
@@ -241,7 +513,7 @@ func getProviderValue(ctx context.Context, scriptPath, funcName string) string {
 	ctx, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
 	defer cancel()
 
-	script := fmt.Sprintf("source %q && %s 2>/dev/null", scriptPath, funcName)
+	script := fmt.Sprintf("source %s && %s 2>/dev/null", shell.Quote(scriptPath), funcName)
 	cmd := exec.CommandContext(ctx, "bash", "-c", script)
 	output, err := cmd.Output()
 	if err != nil {
@@ -250,34 +522,133 @@ func getProviderValue(ctx context.Context, scriptPath, funcName string) string {
 	return strings.TrimSpace(string(output))
 }
 
-// getProviderTasks calls plugin_items and parses the output
-func getProviderTasks(ctx context.Context, scriptPath string) ([]TaskrunnerTask, error) {
+// providerV2Marker is the first line of plugin_items_json output, telling
+// it apart from plugin_items output
+const providerV2Marker = "#nunchux-items v2"
+
+// getProviderTasks lists the tasks of a provider script in dir. Scripts
+// that define plugin_items_json use protocol v2 (JSON lines); others fall
+// back to plugin_items (v1, tab-separated lines).
+func getProviderTasks(ctx context.Context, scriptPath, dir string) ([]TaskrunnerTask, error) {
 	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
 	defer cancel()
 
-	// Get tmux pane's current directory (not nunchux's cwd)
-	paneDir := getPaneCurrentPath()
-
-	script := fmt.Sprintf("cd %q 2>/dev/null; source %q && plugin_items 2>/dev/null", paneDir, scriptPath)
+	script := fmt.Sprintf(`cd %s 2>/dev/null; export NUNCHUX_PROTOCOL=2; source %s || exit
+if declare -F plugin_items_json >/dev/null; then
+    echo %s
+    plugin_items_json 2>/dev/null
+else
+    plugin_items 2>/dev/null
+fi`, shell.Quote(dir), shell.Quote(scriptPath), shell.Quote(providerV2Marker))
 	cmd := exec.CommandContext(ctx, "bash", "-c", script)
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, err
 	}
 
+	if items, ok := strings.CutPrefix(string(output), providerV2Marker+"\n"); ok {
+		return parseProviderItems(items, dir, ""), nil
+	}
+	return parseProviderTasks(string(output), ""), nil
+}
+
+// providerItem is one line of protocol v2 output
+type providerItem struct {
+	Name   string          `json:"name"`
+	Cmd    string          `json:"cmd"`
+	Desc   string          `json:"desc"`
+	Dir    string          `json:"dir"`
+	Group  string          `json:"group"`
+	Icon   string          `json:"icon"`
+	Params []providerParam `json:"params"`
+}
+
+// providerParam is a task parameter in protocol v2 output
+type providerParam struct {
+	Name      string `json:"name"`
+	Default   string `json:"default"`
+	Optional  bool   `json:"optional"`
+	Kind      string `json:"kind"` // "positional" (default), "variable" or "rest"
+	Separator string `json:"separator"`
+}
+
+// parseProviderItems parses protocol v2 output: one JSON object per line.
+// Relative directories are relative to dir, where the provider ran. Without
+// a command, a task uses the run template, like in v1. Tasks of a group are
+// moved together, where the group first appears.
+func parseProviderItems(output, dir, runTemplate string) []TaskrunnerTask {
 	var tasks []TaskrunnerTask
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	var groups []string
+	grouped := make(map[string][]TaskrunnerTask)
+	for _, line := range strings.Split(output, "\n") {
+		var item providerItem
+		if line = strings.TrimSpace(line); line == "" || json.Unmarshal([]byte(line), &item) != nil || item.Name == "" {
+			continue
+		}
+		if item.Cmd == "" {
+			if runTemplate == "" {
+				continue
+			}
+			item.Cmd = strings.ReplaceAll(runTemplate, "{task}", shell.Quote(item.Name))
+		}
+		if item.Dir != "" && !filepath.IsAbs(item.Dir) {
+			item.Dir = filepath.Join(dir, item.Dir)
+		}
+
+		task := TaskrunnerTask{
+			TaskName:    item.Name,
+			Cmd:         item.Cmd,
+			Description: item.Desc,
+			Dir:         item.Dir,
+			Group:       item.Group,
+			Icon:        item.Icon,
+		}
+		for _, p := range item.Params {
+			param := TaskParam{Name: p.Name, Default: p.Default, Optional: p.Optional, Separator: p.Separator}
+			switch p.Kind {
+			case "variable":
+				param.Kind = ParamVariable
+			case "rest":
+				param.Kind = ParamRest
+			}
+			task.Params = append(task.Params, param)
+		}
+
+		if _, ok := grouped[task.Group]; !ok {
+			groups = append(groups, task.Group)
+		}
+		grouped[task.Group] = append(grouped[task.Group], task)
+	}
+
+	for _, group := range groups {
+		tasks = append(tasks, grouped[group]...)
+	}
+	return tasks
+}
+
+// parseProviderTasks parses "name<TAB>cmd<TAB>description" lines. With a
+// run template, the command may be empty or left out, and the template is
+// used with {task} replaced by the task name.
+func parseProviderTasks(output, runTemplate string) []TaskrunnerTask {
+	var tasks []TaskrunnerTask
+	lines := strings.Split(strings.TrimSpace(output), "\n")
 	for _, line := range lines {
 		if line == "" {
 			continue
 		}
 		parts := strings.SplitN(line, "\t", 3)
-		if len(parts) < 2 {
+		if parts[0] == "" || (len(parts) < 2 && runTemplate == "") {
 			continue
 		}
-		task := TaskrunnerTask{
-			TaskName: parts[0],
-			Cmd:      parts[1],
+		task := TaskrunnerTask{TaskName: parts[0]}
+		if len(parts) > 1 {
+			task.Cmd = parts[1]
+		}
+		if task.Cmd == "" {
+			if runTemplate == "" {
+				continue
+			}
+			task.Cmd = strings.ReplaceAll(runTemplate, "{task}", shell.Quote(task.TaskName))
 		}
 		if len(parts) > 2 {
 			task.Description = parts[2]
@@ -285,5 +656,5 @@ func getProviderTasks(ctx context.Context, scriptPath string) ([]TaskrunnerTask,
 		tasks = append(tasks, task)
 	}
 
-	return tasks, nil
+	return tasks
 }
@@ -0,0 +1,238 @@
+package items
+
+import (
+	"strings"
+)
+
+// Minimal TOML reading for project manifests (Cargo.toml, pyproject.toml).
+// It understands table and array-of-table headers, key = value pairs, and
+// values that are strings, arrays of strings or inline tables. Values are
+// kept raw and decoded on demand by the toml* helpers.
+
+// tomlTable is one table of a TOML document
+type tomlTable struct {
+	Name   string            // Dotted header name, "" for the root table
+	Keys   []string          // Keys in file order
+	Values map[string]string // Raw values by key
+}
+
+// parseTOML splits a document into its tables, in file order
+func parseTOML(content string) []tomlTable {
+	tables := []tomlTable{{Values: make(map[string]string)}}
+	current := &tables[0]
+
+	lines := strings.Split(content, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(tomlStripComment(lines[i]))
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			name := strings.Trim(line, "[]")
+			tables = append(tables, tomlTable{Name: tomlDottedName(name), Values: make(map[string]string)})
+			current = &tables[len(tables)-1]
+			continue
+		}
+
+		key, value, ok := tomlSplitKey(line)
+		if !ok {
+			continue
+		}
+
+		// Multi-line arrays, inline tables and strings continue until closed
+		for !tomlComplete(value) && i+1 < len(lines) {
+			i++
+			value += "\n" + tomlStripComment(lines[i])
+		}
+
+		if _, exists := current.Values[key]; !exists {
+			current.Keys = append(current.Keys, key)
+		}
+		current.Values[key] = strings.TrimSpace(value)
+	}
+	return tables
+}
+
+// tomlFind returns the first table with the given name, or nil
+func tomlFind(tables []tomlTable, name string) *tomlTable {
+	for i := range tables {
+		if tables[i].Name == name {
+			return &tables[i]
+		}
+	}
+	return nil
+}
+
+// tomlFindAll returns every table with the given name ([[bin]] entries)
+func tomlFindAll(tables []tomlTable, name string) []tomlTable {
+	var found []tomlTable
+	for _, t := range tables {
+		if t.Name == name {
+			found = append(found, t)
+		}
+	}
+	return found
+}
+
+// String returns a key's value as a string, or "" if it isn't one
+func (t *tomlTable) String(key string) string {
+	if t == nil {
+		return ""
+	}
+	return tomlString(t.Values[key])
+}
+
+// tomlString decodes a basic or literal string value
+func tomlString(raw string) string {
+	for _, quote := range []string{`"""`, `'''`, `"`, `'`} {
+		if len(raw) >= 2*len(quote) && strings.HasPrefix(raw, quote) && strings.HasSuffix(raw, quote) {
+			s := raw[len(quote) : len(raw)-len(quote)]
+			if quote[0] == '"' {
+				s = strings.NewReplacer(`\"`, `"`, `\\`, `\`, `\n`, "\n", `\t`, "\t").Replace(s)
+			}
+			return strings.TrimPrefix(s, "\n")
+		}
+	}
+	return ""
+}
+
+// tomlStrings decodes an array of strings, skipping other elements
+func tomlStrings(raw string) []string {
+	raw = strings.TrimSpace(raw)
+	if !strings.HasPrefix(raw, "[") || !strings.HasSuffix(raw, "]") {
+		return nil
+	}
+	var values []string
+	for _, elem := range tomlSplitTopLevel(raw[1:len(raw)-1], ',') {
+		if s := tomlString(strings.TrimSpace(elem)); s != "" {
+			values = append(values, s)
+		}
+	}
+	return values
+}
+
+// tomlInline decodes an inline table into raw values
+func tomlInline(raw string) map[string]string {
+	raw = strings.TrimSpace(raw)
+	if !strings.HasPrefix(raw, "{") || !strings.HasSuffix(raw, "}") {
+		return nil
+	}
+	values := make(map[string]string)
+	for _, pair := range tomlSplitTopLevel(raw[1:len(raw)-1], ',') {
+		if key, value, ok := tomlSplitKey(strings.TrimSpace(pair)); ok {
+			values[key] = strings.TrimSpace(value)
+		}
+	}
+	return values
+}
+
+// tomlSplitKey splits "key = value", unquoting the key
+func tomlSplitKey(line string) (string, string, bool) {
+	parts := tomlSplitTopLevel(line, '=')
+	if len(parts) < 2 {
+		return "", "", false
+	}
+	key := strings.TrimSpace(parts[0])
+	if unquoted := tomlString(key); unquoted != "" {
+		key = unquoted
+	}
+	return key, strings.TrimSpace(strings.Join(parts[1:], "=")), key != ""
+}
+
+// tomlDottedName normalizes a header like `tool."my.tool" . scripts`
+func tomlDottedName(name string) string {
+	var parts []string
+	for _, part := range tomlSplitTopLevel(name, '.') {
+		part = strings.TrimSpace(part)
+		if unquoted := tomlString(part); unquoted != "" {
+			part = unquoted
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ".")
+}
+
+// tomlSplitTopLevel splits s on sep outside strings, arrays and inline tables
+func tomlSplitTopLevel(s string, sep byte) []string {
+	var parts []string
+	depth := 0
+	var quote byte
+	start := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+		case c == sep && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// tomlComplete reports whether a raw value has no unclosed brackets or
+// multi-line strings
+func tomlComplete(value string) bool {
+	for _, quote := range []string{`"""`, `'''`} {
+		if strings.Count(value, quote)%2 == 1 {
+			return false
+		}
+	}
+	if strings.Contains(value, `"""`) || strings.Contains(value, `'''`) {
+		return true
+	}
+
+	depth := 0
+	var quote byte
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+		}
+	}
+	return depth <= 0
+}
+
+// tomlStripComment removes a trailing comment outside strings
+func tomlStripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
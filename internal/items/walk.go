@@ -0,0 +1,157 @@
+package items
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"nunchux/internal/ignore"
+)
+
+// errWalkLimit stops a walk once it has reported enough files
+var errWalkLimit = errors.New("walk limit reached")
+
+// walkOptions select the files a dirbrowser walk reports
+type walkOptions struct {
+	Depth          int             // Max depth of files, 1 = the root's own files
+	Glob           string          // Only files whose name matches (empty = all)
+	Exclude        *ignore.Matcher // Skipped files and directories
+	Gitignore      *ignore.Tree    // Also skip what ignore files below root ignore
+	FollowSymlinks bool            // Report linked files and descend into linked directories
+	Limit          int             // Stop after this many files (0 = no limit)
+}
+
+// walkFile is a file found by walkFiles
+type walkFile struct {
+	Path    string // Path below root, as given
+	RelPath string // Slash-separated path relative to root
+	Info    fs.FileInfo
+}
+
+// walkFiles calls fn for every regular file under root that opts select.
+// Unreadable directories below root are skipped; only a failure to read
+// root itself or a cancelled ctx is returned.
+func walkFiles(ctx context.Context, root string, opts walkOptions, fn func(walkFile)) error {
+	w := &walker{ctx: ctx, opts: opts, fn: fn, visited: make(map[string]bool)}
+	if real, err := filepath.EvalSymlinks(root); err == nil {
+		w.visited[real] = true
+	}
+	err := w.walk(root, root, "")
+	if errors.Is(err, errWalkLimit) {
+		return nil
+	}
+	return err
+}
+
+type walker struct {
+	ctx     context.Context
+	opts    walkOptions
+	fn      func(walkFile)
+	count   int
+	visited map[string]bool // Real paths of directories entered through links
+}
+
+// walk walks dir, which is found at rel (relative to the walk's root, ""
+// for the root itself). fsDir is where dir really is when it was reached
+// through a symlink.
+func (w *walker) walk(fsDir, root, rel string) error {
+	depth := w.opts.Depth
+	if depth <= 0 {
+		depth = 1
+	}
+	return filepath.WalkDir(fsDir, func(path string, d fs.DirEntry, err error) error {
+		if ctxErr := w.ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			if path == fsDir && rel == "" {
+				return err
+			}
+			return nil // Vanished or unreadable; not worth failing over
+		}
+		if path == fsDir {
+			return nil
+		}
+
+		sub, _ := filepath.Rel(fsDir, path)
+		relPath := filepath.ToSlash(filepath.Join(rel, sub))
+		level := strings.Count(relPath, "/") + 1
+		isDir := d.IsDir()
+
+		if d.Type()&fs.ModeSymlink != 0 {
+			if !w.opts.FollowSymlinks {
+				return nil
+			}
+			info, err := os.Stat(path)
+			if err != nil || w.skip(relPath, info.IsDir()) {
+				return nil // Dangling or excluded
+			}
+			if info.IsDir() {
+				if level >= depth {
+					return nil
+				}
+				real, err := filepath.EvalSymlinks(path)
+				if err != nil || w.visited[real] || isAncestor(real, filepath.Dir(path)) {
+					return nil // Loop, or a directory walked already
+				}
+				w.visited[real] = true
+				return w.walk(real, root, relPath)
+			}
+			return w.report(filepath.Join(root, relPath), relPath, info)
+		}
+
+		if w.skip(relPath, isDir) {
+			if isDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if isDir {
+			if level >= depth {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		return w.report(filepath.Join(root, relPath), relPath, info)
+	})
+}
+
+// skip reports whether the exclude patterns or ignore files skip relPath
+func (w *walker) skip(relPath string, isDir bool) bool {
+	return w.opts.Exclude.Match(relPath, isDir) || w.opts.Gitignore.Match(relPath, isDir)
+}
+
+// isAncestor reports whether real is dir or one of its parents, after
+// resolving links in dir
+func isAncestor(real, dir string) bool {
+	dir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return false
+	}
+	return dir == real || strings.HasPrefix(dir, strings.TrimSuffix(real, string(filepath.Separator))+string(filepath.Separator))
+}
+
+// report passes a file to fn if it matches the glob
+func (w *walker) report(path, relPath string, info fs.FileInfo) error {
+	if w.opts.Glob != "" {
+		if ok, _ := filepath.Match(w.opts.Glob, info.Name()); !ok {
+			return nil
+		}
+	}
+	w.fn(walkFile{Path: path, RelPath: relPath, Info: info})
+	w.count++
+	if w.opts.Limit > 0 && w.count >= w.opts.Limit {
+		return errWalkLimit
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package items
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"nunchux/internal/config"
+	"nunchux/internal/ignore"
+)
+
+// walkTree creates files (relative paths) under a temp dir
+func walkTree(t *testing.T, files ...string) string {
+	t.Helper()
+	root := t.TempDir()
+	for _, f := range files {
+		writeFile(t, filepath.Join(root, f), f)
+	}
+	return root
+}
+
+func walkPaths(t *testing.T, root string, opts walkOptions) []string {
+	t.Helper()
+	var paths []string
+	err := walkFiles(context.Background(), root, opts, func(f walkFile) {
+		if f.Path != filepath.Join(root, f.RelPath) {
+			t.Errorf("path %q does not match rel path %q", f.Path, f.RelPath)
+		}
+		paths = append(paths, f.RelPath)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func TestWalkFiles(t *testing.T) {
+	root := walkTree(t,
+		"a.conf", "b.txt", "app.log",
+		"sub/c.conf", "sub/deep/d.conf",
+		"node_modules/pkg/e.conf", "sub/node_modules/f.conf",
+	)
+	exclude := ignore.New(ignore.SplitList("node_modules, *.log"))
+
+	tests := []struct {
+		name string
+		opts walkOptions
+		want []string
+	}{
+		{"default depth", walkOptions{}, []string{"a.conf", "app.log", "b.txt"}},
+		{"depth", walkOptions{Depth: 2}, []string{"a.conf", "app.log", "b.txt", "sub/c.conf"}},
+		{"glob", walkOptions{Depth: 3, Glob: "*.conf"}, []string{"a.conf", "node_modules/pkg/e.conf", "sub/c.conf", "sub/deep/d.conf", "sub/node_modules/f.conf"}},
+		{"exclude", walkOptions{Depth: 3, Exclude: exclude}, []string{"a.conf", "b.txt", "sub/c.conf", "sub/deep/d.conf"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := walkPaths(t, root, tt.opts); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWalkFilesSymlinks(t *testing.T) {
+	root := walkTree(t, "a.txt", "real/b.txt")
+	other := walkTree(t, "c.txt")
+	for link, target := range map[string]string{
+		"link.txt":  filepath.Join(root, "a.txt"),
+		"linkdir":   other,
+		"loop":      root,
+		"dangling":  filepath.Join(root, "missing"),
+		"real/back": filepath.Join(root, "real"),
+	} {
+		if err := os.Symlink(target, filepath.Join(root, link)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got, want := walkPaths(t, root, walkOptions{Depth: 5}), []string{"a.txt", "real/b.txt"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("not following: got %v, want %v", got, want)
+	}
+	// Links to a directory containing them are loops and not followed
+	want := []string{"a.txt", "link.txt", "linkdir/c.txt", "real/b.txt"}
+	if got := walkPaths(t, root, walkOptions{Depth: 5, FollowSymlinks: true}); !reflect.DeepEqual(got, want) {
+		t.Errorf("following: got %v, want %v", got, want)
+	}
+}
+
+func TestWalkFilesLimit(t *testing.T) {
+	root := walkTree(t, "1", "2", "3", "4", "5")
+	if got := walkPaths(t, root, walkOptions{Limit: 3}); len(got) != 3 {
+		t.Errorf("got %d files, want 3", len(got))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := walkFiles(ctx, root, walkOptions{}, func(walkFile) {}); err == nil {
+		t.Error("cancelled walk should fail")
+	}
+	if err := walkFiles(context.Background(), filepath.Join(root, "missing"), walkOptions{}, func(walkFile) {}); err == nil {
+		t.Error("walk of a missing root should fail")
+	}
+}
+
+func TestDirbrowserListFiles(t *testing.T) {
+	root := walkTree(t, "top.conf", "app/settings.conf", "app/cache/x.conf", "app/notes.txt")
+	settings := config.DefaultSettings()
+	settings.ExcludePatterns = "cache"
+	db := &DirbrowserItem{
+		Dirbrowser: config.Dirbrowser{Directory: root, Depth: 3, Glob: "*.conf", Sort: "alphabetical", SortDirection: "ascending"},
+		Settings:   &settings,
+	}
+
+	entries, err := db.ListFiles(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []FileEntry{
+		{Path: filepath.Join(root, "app/settings.conf"), RelPath: "app/settings.conf", Folder: "app", Filename: "settings.conf"},
+		{Path: filepath.Join(root, "top.conf"), RelPath: "top.conf", Folder: "top.conf", Filename: "top.conf"},
+	}
+	for i := range entries {
+		entries[i].ModTime = want[0].ModTime
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("ListFiles = %+v, want %+v", entries, want)
+	}
+	if n := db.getFileCount(context.Background()); n != 2 {
+		t.Errorf("getFileCount = %d, want 2", n)
+	}
+}
+
+func TestDirbrowserGitignore(t *testing.T) {
+	root := walkTree(t,
+		".git/HEAD", ".gitignore", "main.go", "app.log", "dist/bundle.js",
+		"pkg/.gitignore", "pkg/gen.go", "pkg/lib.go",
+	)
+	writeFile(t, filepath.Join(root, ".gitignore"), "dist/\n")
+	writeFile(t, filepath.Join(root, "pkg/.gitignore"), "gen.go\n")
+	// No global excludes file: git reads no user or system config
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("GIT_CONFIG_GLOBAL", "/dev/null")
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	settings := config.DefaultSettings()
+	settings.ExcludePatterns = ".git, *.log"
+	db := &DirbrowserItem{
+		Dirbrowser: config.Dirbrowser{Directory: root, Depth: 3, Exclude: []string{"!*.log", ".gitignore"}},
+		Settings:   &settings,
+	}
+	if got, want := walkPaths(t, root, db.walkOptions()), []string{"app.log", "dist/bundle.js", "main.go", "pkg/gen.go", "pkg/lib.go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("exclude: got %v, want %v", got, want)
+	}
+
+	db.Dirbrowser.RespectGitignore = true
+	if got, want := walkPaths(t, root, db.walkOptions()), []string{"app.log", "main.go", "pkg/lib.go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("respect_gitignore: got %v, want %v", got, want)
+	}
+}
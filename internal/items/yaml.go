@@ -0,0 +1,215 @@
+package items
+
+import (
+	"strings"
+)
+
+// Minimal YAML reading for taskrunner sources. This understands block
+// mappings and plain scalars, which is all that is needed to list the keys
+// of a Taskfile's tasks or a compose file's services. Anything fancier
+// (anchors, flow mappings, multi-line scalars) is skipped over, not parsed.
+
+// yamlEntry is one key of a block mapping
+type yamlEntry struct {
+	Key   string
+	Value string   // Inline scalar value, if any
+	Lines []string // Nested lines, with their indentation
+}
+
+// yamlMapping returns the entries of the block mapping under the top-level
+// key, in file order
+func yamlMapping(content, key string) []yamlEntry {
+	var block []string
+	inBlock := false
+	for _, line := range strings.Split(content, "\n") {
+		if yamlSkip(line) {
+			continue
+		}
+		if yamlIndent(line) == 0 {
+			k, _ := yamlKeyValue(line)
+			inBlock = k == key
+			continue
+		}
+		if inBlock {
+			block = append(block, line)
+		}
+	}
+	return yamlEntries(block)
+}
+
+// yamlList returns the scalar items of the block sequence under the
+// top-level key
+func yamlList(content, key string) []string {
+	var items []string
+	inBlock := false
+	for _, line := range strings.Split(content, "\n") {
+		if yamlSkip(line) {
+			continue
+		}
+		if yamlIndent(line) == 0 && !strings.HasPrefix(line, "-") {
+			k, _ := yamlKeyValue(line)
+			inBlock = k == key
+			continue
+		}
+		if item, ok := strings.CutPrefix(strings.TrimSpace(line), "- "); ok && inBlock {
+			items = append(items, yamlScalar(item))
+		}
+	}
+	return items
+}
+
+// yamlEntries splits nested lines into the entries at their first indentation
+func yamlEntries(lines []string) []yamlEntry {
+	var entries []yamlEntry
+	indent := -1
+	for _, line := range lines {
+		if yamlSkip(line) {
+			continue
+		}
+		n := yamlIndent(line)
+		if indent < 0 {
+			indent = n
+		}
+		if n < indent {
+			break
+		}
+		if n == indent {
+			key, value := yamlKeyValue(line)
+			if key == "" {
+				continue
+			}
+			entries = append(entries, yamlEntry{Key: key, Value: value})
+			continue
+		}
+		if len(entries) > 0 {
+			last := &entries[len(entries)-1]
+			last.Lines = append(last.Lines, line)
+		}
+	}
+	return entries
+}
+
+// Scalar returns the inline value of a direct child key of the entry
+func (e yamlEntry) Scalar(key string) string {
+	for _, child := range yamlEntries(e.Lines) {
+		if child.Key == key {
+			return child.Value
+		}
+	}
+	return ""
+}
+
+// Child returns a direct child entry of the entry
+func (e yamlEntry) Child(key string) yamlEntry {
+	for _, child := range yamlEntries(e.Lines) {
+		if child.Key == key {
+			return child
+		}
+	}
+	return yamlEntry{}
+}
+
+// List returns a direct child key's sequence, written as a flow sequence
+// ([a, b]) or as a block of "- item" lines
+func (e yamlEntry) List(key string) []string {
+	child := e.Child(key)
+	if flow, ok := strings.CutPrefix(child.Value, "["); ok {
+		var items []string
+		for _, item := range strings.Split(strings.TrimSuffix(flow, "]"), ",") {
+			if item = yamlScalar(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		return items
+	}
+
+	var items []string
+	for _, line := range child.Lines {
+		if item, ok := strings.CutPrefix(strings.TrimSpace(line), "- "); ok {
+			items = append(items, yamlScalar(item))
+		}
+	}
+	return items
+}
+
+// Has reports whether the entry has a direct child key
+func (e yamlEntry) Has(key string) bool {
+	for _, child := range yamlEntries(e.Lines) {
+		if child.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// yamlHasKey reports whether content has a top-level key
+func yamlHasKey(content, key string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		if !yamlSkip(line) && yamlIndent(line) == 0 {
+			if k, _ := yamlKeyValue(line); k == key {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// yamlSkip reports whether a line is blank, a comment or a document marker
+func yamlSkip(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed == "" || strings.HasPrefix(trimmed, "#") || trimmed == "---"
+}
+
+func yamlIndent(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
+
+// yamlKeyValue splits "key: value" or "key:". Keys may contain colons
+// (task names like "docker:build") as long as they are not followed by a
+// space. List items and lines without a key return an empty key.
+func yamlKeyValue(line string) (string, string) {
+	line = strings.TrimSpace(line)
+	if strings.HasPrefix(line, "- ") || line == "-" {
+		return "", ""
+	}
+
+	var key, value string
+	if line[0] == '"' || line[0] == '\'' {
+		end := strings.IndexByte(line[1:], line[0])
+		if end < 0 {
+			return "", ""
+		}
+		key = line[1 : end+1]
+		rest := strings.TrimSpace(line[end+2:])
+		if !strings.HasPrefix(rest, ":") {
+			return "", ""
+		}
+		value = rest[1:]
+	} else if idx := strings.Index(line, ": "); idx >= 0 {
+		key, value = line[:idx], line[idx+2:]
+	} else if strings.HasSuffix(line, ":") {
+		key = strings.TrimSuffix(line, ":")
+	} else {
+		return "", ""
+	}
+
+	return key, yamlScalar(value)
+}
+
+// yamlScalar unquotes a plain or quoted scalar and strips trailing comments
+func yamlScalar(value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return ""
+	}
+	if value[0] == '"' || value[0] == '\'' {
+		if end := strings.IndexByte(value[1:], value[0]); end >= 0 {
+			return value[1 : end+1]
+		}
+		return value[1:]
+	}
+	if idx := strings.Index(value, " #"); idx >= 0 {
+		value = value[:idx]
+	}
+	return strings.TrimSpace(value)
+}
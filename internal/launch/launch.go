@@ -0,0 +1,324 @@
+package launch
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"nunchux/internal/config"
+	"nunchux/internal/history"
+	"nunchux/internal/items"
+	"nunchux/internal/shell"
+	"nunchux/internal/tmux"
+)
+
+// Event describes a launch, for subscribers of the control API
+type Event struct {
+	Type   string        `json:"type"` // "launch", "select" or "kill"
+	Name   string        `json:"name"`
+	Kind   string        `json:"kind"` // "app", "task" or "file"
+	Action config.Action `json:"action,omitempty"`
+	Dir    string        `json:"dir,omitempty"`
+	Time   time.Time     `json:"time"`
+}
+
+// Launcher launches registry items through tmux
+type Launcher struct {
+	Registry *items.Registry
+	Tmux     *tmux.Client
+	Publish  func(Event) // Called after each launch (nil = no events)
+}
+
+// New creates a launcher
+func New(registry *items.Registry, tmuxClient *tmux.Client) *Launcher {
+	return &Launcher{Registry: registry, Tmux: tmuxClient}
+}
+
+func (l *Launcher) publish(e Event) {
+	if l.Publish != nil {
+		e.Time = time.Now()
+		l.Publish(e)
+	}
+}
+
+// App launches an app with action (empty = primary). If the app is already
+// running, its window is selected instead, unless launching in background.
+func (l *Launcher) App(app *items.AppItem, action config.Action, dir string) error {
+	name := app.Name()
+	if action == "" {
+		action = app.GetPrimaryAction()
+	}
+
+	if action != config.ActionBackgroundWindow && l.Tmux.IsWindowRunning(name) {
+		l.publish(Event{Type: "select", Name: name, Kind: "app"})
+		return l.Tmux.SelectWindow(name)
+	}
+
+	settings := l.Registry.Settings
+	err := l.Tmux.Launch(tmux.LaunchOptions{
+		Action:    action,
+		Name:      name,
+		Cmd:       app.App.Cmd,
+		Dir:       dir,
+		Width:     app.GetWidth(),
+		Height:    app.GetHeight(),
+		MaxWidth:  settings.MaxPopupWidth,
+		MaxHeight: settings.MaxPopupHeight,
+		Hooks:     settings.Hooks.Merge(app.App.Hooks),
+		IsApp:     true,
+	})
+	if err != nil {
+		return err
+	}
+	l.publish(Event{Type: "launch", Name: name, Kind: "app", Action: action, Dir: dir})
+	return nil
+}
+
+// Task runs a taskrunner task with action (empty = primary) and parameter
+// values args, reusing the task's window if it already has one
+func (l *Launcher) Task(tr *items.TaskrunnerItem, action config.Action, dir string, args []string) error {
+	windowName := tr.WindowName()
+	if action == "" {
+		action = tr.GetPrimaryAction()
+	}
+	if tr.Task.Dir != "" {
+		dir = tr.Task.Dir
+	}
+	if action == config.ActionWatch {
+		return l.watch(tr, dir, args)
+	}
+
+	cmd, err := l.taskCmd(tr, action, windowName, dir, args)
+	if err != nil {
+		return err
+	}
+	if err := l.launchTask(windowName, cmd, dir, action, l.useTaskPane(action)); err != nil {
+		return err
+	}
+	l.publish(Event{Type: "launch", Name: tr.Name(), Kind: "task", Action: action, Dir: dir})
+	return nil
+}
+
+// taskCmd builds the wrapper command for one recorded run of a task
+func (l *Launcher) taskCmd(tr *items.TaskrunnerItem, action config.Action, windowName, dir string, args []string) (string, error) {
+	// Hooks run inside the task wrapper, so they see the task's exit code
+	settings := l.Registry.Settings
+	hooks := settings.Hooks.Merge(tr.Config.Hooks)
+	taskCmd, err := tr.Task.Command(args)
+	if err != nil {
+		return "", err
+	}
+	cmd := tmux.HookScript(taskCmd, hooks, tr.Name(), action, dir)
+	run, err := l.newRun(tr.Name(), tr.Runner, tr.Task.FullName(), taskCmd, dir, action, args)
+	if err != nil {
+		return "", err
+	}
+	return TaskCmd(settings, cmd, windowName, run), nil
+}
+
+// Rerun runs the exact command of a past task run again, in its
+// directory, as a new recorded run
+func (l *Launcher) Rerun(past history.Run, action config.Action) error {
+	if action == "" {
+		action = past.Action
+	}
+	if action == "" || action == config.ActionWatch {
+		action = config.ActionWindow
+	}
+	// Same as the task's own window name, "runner » task"
+	windowName := past.Runner + " » " + past.Task
+
+	run, err := l.newRun(past.Name, past.Runner, past.Task, past.Cmd, past.Dir, action, past.Args)
+	if err != nil {
+		return err
+	}
+
+	cmd := TaskCmd(l.Registry.Settings, past.Cmd, windowName, run)
+	if err := l.launchTask(windowName, cmd, past.Dir, action, l.useTaskPane(action)); err != nil {
+		return err
+	}
+	l.publish(Event{Type: "launch", Name: past.Name, Kind: "task", Action: action, Dir: past.Dir})
+	return nil
+}
+
+// launchTask opens a task wrapper command in its window (or popup),
+// reusing the window if it already has one. With taskPane, window actions
+// run in the current window's task pane instead.
+func (l *Launcher) launchTask(windowName, cmd, dir string, action config.Action, taskPane bool) error {
+	settings := l.Registry.Settings
+	return l.Tmux.Launch(tmux.LaunchOptions{
+		Action:       action,
+		Name:         windowName,
+		Cmd:          cmd,
+		Dir:          dir,
+		Width:        settings.PopupWidth,
+		Height:       settings.PopupHeight,
+		MaxWidth:     settings.MaxPopupWidth,
+		MaxHeight:    settings.MaxPopupHeight,
+		IsApp:        false,
+		IsTaskrunner: true,
+		ReuseWindow:  !taskPane && l.Tmux.IsWindowRunning(windowName),
+		TaskPane:     taskPane,
+		PaneSize:     settings.TaskPaneSize,
+		SuccessIcon:  settings.TaskrunnerIconSuccess,
+		FailedIcon:   settings.TaskrunnerIconFailed,
+		RunningIcon:  settings.TaskrunnerIconRunning,
+	})
+}
+
+// useTaskPane reports whether a task launched with action runs in the task
+// pane (task_target = pane) rather than a window of its own
+func (l *Launcher) useTaskPane(action config.Action) bool {
+	return l.Registry.Settings.TaskTarget == config.TaskTargetPane &&
+		(action == config.ActionWindow || action == config.ActionBackgroundWindow)
+}
+
+// newRun starts a history record for a task launch, and saves it as the
+// project's last launch. Notifications that target a client remember the
+// one launching it.
+func (l *Launcher) newRun(name, runner, task, cmd, dir string, action config.Action, args []string) (*history.Run, error) {
+	run, err := history.NewRun(name, runner, task, cmd, dir, action, args)
+	if err != nil {
+		return nil, err
+	}
+	switch l.Registry.Settings.TaskrunnerNotify {
+	case tmux.NotifyTmux, tmux.NotifyBell:
+		run.Client = l.Tmux.ClientTTY()
+	}
+
+	// Remember the launch for `nunchux task rerun`, in the directory the
+	// task runs in
+	launch := *run
+	if launch.Dir == "" {
+		launch.Dir = l.Registry.WorkDir()
+	}
+	if err := history.SaveLaunch(launch); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// File opens a dirbrowser file in the user's editor
+func (l *Launcher) File(db *items.DirbrowserItem, path string, action config.Action) error {
+	if action == "" {
+		action = db.GetPrimaryAction()
+	}
+
+	// Get editor from environment
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "nvim"
+	}
+
+	cmd := editor + " " + shell.Quote(path)
+	windowName := filepath.Base(path)
+	if action == config.ActionPopup {
+		windowName = db.Dirbrowser.Name + " | " + filepath.Base(path)
+	}
+
+	settings := l.Registry.Settings
+	err := l.Tmux.Launch(tmux.LaunchOptions{
+		Action:    action,
+		Name:      windowName,
+		Cmd:       cmd,
+		Width:     db.GetWidth(),
+		Height:    db.GetHeight(),
+		MaxWidth:  settings.MaxPopupWidth,
+		MaxHeight: settings.MaxPopupHeight,
+		Hooks:     settings.Hooks.Merge(db.Dirbrowser.Hooks),
+		IsApp:     false,
+	})
+	if err != nil {
+		return err
+	}
+	l.publish(Event{Type: "launch", Name: path, Kind: "file", Action: action})
+	return nil
+}
+
+// Kill kills the window of an app or task by item name
+func (l *Launcher) Kill(name string) error {
+	windowName := name
+	if tr := l.Registry.FindTaskrunnerItem(name); tr != nil {
+		windowName = tr.WindowName()
+	} else if p := l.Registry.FindPipeline(name); p != nil {
+		windowName = p.WindowName()
+	}
+	if err := l.Tmux.KillWindowByPrefix(windowName); err != nil {
+		return err
+	}
+	l.publish(Event{Type: "kill", Name: name})
+	return nil
+}
+
+// TaskCmd wraps a task command with status indicator and wait. If run is
+// not nil, the output is logged to run.Log and the run is recorded in the
+// task history when the command exits.
+func TaskCmd(settings *config.Settings, cmd, windowName string, run *history.Run) string {
+	var script strings.Builder
+	fmt.Fprintf(&script, "source %s 2>/dev/null || true\n", shell.Quote(settings.BinDir+"/nunchux-run"))
+	// In the task pane the pane title shows the task, since the window is
+	// the user's own
+	fmt.Fprintf(&script, `__nunchux_title() {
+    if [[ -n $%s ]]; then
+        tmux select-pane -t "$TMUX_PANE" -T "$1" 2>/dev/null
+    else
+        tmux rename-window -t "$TMUX_PANE" "$1" 2>/dev/null
+    fi
+}
+[[ -n $%s ]] && __nunchux_title %s
+`, tmux.TaskPaneEnv, tmux.TaskPaneEnv, shell.Quote(windowName+" "+settings.TaskrunnerIconRunning))
+	if run == nil {
+		// The command may be multi-line (hooks)
+		fmt.Fprintf(&script, "%s\nexit_code=$?\n", cmd)
+	} else {
+		script.WriteString(recordScript(cmd, run, settings.TaskrunnerNotify))
+	}
+	fmt.Fprintf(&script, `echo
+if [[ $exit_code -eq 0 ]]; then
+    __nunchux_title %s
+    echo -e "\033[32m✓ Task completed successfully\033[0m"
+else
+    __nunchux_title %s
+    echo -e "\033[31m✗ Task failed with exit code $exit_code\033[0m"
+fi
+echo
+echo "Press any key to close..."
+read -n 1 -s
+`, shell.Quote(windowName+" "+settings.TaskrunnerIconSuccess), shell.Quote(windowName+" "+settings.TaskrunnerIconFailed))
+	return "bash -c " + shell.Quote("\n"+script.String())
+}
+
+// recordScript runs cmd with its output logged to run.Log, then records
+// the run and sends the completion notification. Windows log through
+// pipe-pane, so the task keeps its terminal; popups have no pane of their
+// own and tee instead.
+func recordScript(cmd string, run *history.Run, notify string) string {
+	exe, err := os.Executable()
+	if err != nil {
+		exe = "nunchux"
+	}
+	log := shell.Quote(run.Log)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "__nunchux_task() (\n%s\n)\n", cmd)
+	if run.Action == config.ActionPopup {
+		b.WriteString("__nunchux_task 2>&1 | tee -a " + log + "\nexit_code=${PIPESTATUS[0]}\n")
+	} else {
+		fmt.Fprintf(&b, `tmux pipe-pane -t "$TMUX_PANE" %s 2>/dev/null
+__nunchux_task
+exit_code=$?
+tmux pipe-pane -t "$TMUX_PANE" 2>/dev/null
+`, shell.Quote("cat >> "+log))
+	}
+	record := shell.Quote(exe) + " task record"
+	if notify != "" && notify != tmux.NotifyNone {
+		record += " --notify " + shell.Quote(notify)
+	}
+	fmt.Fprintf(&b, "%s \"$exit_code\" %s >/dev/null 2>&1\n", record, run.Encode())
+	return b.String()
+}
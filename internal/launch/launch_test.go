@@ -0,0 +1,103 @@
+package launch
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"nunchux/internal/config"
+	"nunchux/internal/history"
+	"nunchux/internal/shell"
+)
+
+// fakeCommand writes an executable that appends its arguments, one line
+// per call, to the returned log file
+func fakeCommand(t *testing.T, dir, name string) string {
+	t.Helper()
+	log := filepath.Join(t.TempDir(), name+".log")
+	script := "#!/bin/sh\necho \"$*\" >> " + shell.Quote(log) + "\n"
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return log
+}
+
+// runWrapper runs a task wrapper command with a fake tmux in pane %5
+func runWrapper(t *testing.T, cmd string, env ...string) (output, tmuxLog string) {
+	t.Helper()
+	bin := t.TempDir()
+	logPath := fakeCommand(t, bin, "tmux")
+	c := exec.Command("bash", "-c", cmd)
+	c.Env = append(os.Environ(), append([]string{"PATH=" + bin + ":" + os.Getenv("PATH"), "TMUX_PANE=%5", "NUNCHUX_TASK_PANE="}, env...)...)
+	out, _ := c.CombinedOutput() // read fails at EOF; the exit code is not the task's
+	data, _ := os.ReadFile(logPath)
+	return string(out), string(data)
+}
+
+func TestTaskCmd(t *testing.T) {
+	settings := config.DefaultSettings()
+	settings.BinDir = t.TempDir()
+
+	out, tmuxLog := runWrapper(t, TaskCmd(&settings, "echo hi; false", "just » it's", nil))
+	if !strings.Contains(out, "hi\n") || !strings.Contains(out, "Task failed with exit code 1") {
+		t.Errorf("output = %q", out)
+	}
+	if want := "rename-window -t %5 just » it's ❌\n"; tmuxLog != want {
+		t.Errorf("tmux calls = %q, want %q", tmuxLog, want)
+	}
+
+	// In the task pane, the pane title shows the status instead
+	out, tmuxLog = runWrapper(t, TaskCmd(&settings, "true", "make » test", nil), "NUNCHUX_TASK_PANE=1")
+	if !strings.Contains(out, "Task completed successfully") {
+		t.Errorf("output = %q", out)
+	}
+	if want := "select-pane -t %5 -T make » test 🔄\nselect-pane -t %5 -T make » test ✅\n"; tmuxLog != want {
+		t.Errorf("task pane tmux calls = %q, want %q", tmuxLog, want)
+	}
+}
+
+func TestRecordScript(t *testing.T) {
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bin := t.TempDir()
+	recordLog := fakeCommand(t, bin, "recorder")
+
+	for _, action := range []config.Action{config.ActionPopup, config.ActionWindow} {
+		t.Run(string(action), func(t *testing.T) {
+			os.Remove(recordLog)
+			run := &history.Run{Name: "make:test", Action: action, Log: filepath.Join(t.TempDir(), "run's.log")}
+			script := recordScript("echo out; exit 4", run, "bell")
+			// Record with the fake instead of re-running the test binary
+			script = strings.ReplaceAll(script, shell.Quote(exe), shell.Quote(filepath.Join(bin, "recorder")))
+			out, tmuxLog := runWrapper(t, script)
+
+			if want := "task record --notify bell 4 " + run.Encode() + "\n"; readFile(t, recordLog) != want {
+				t.Errorf("record call = %q, want %q", readFile(t, recordLog), want)
+			}
+			if action == config.ActionPopup {
+				// Popups tee the output to the log
+				if out != "out\n" || readFile(t, run.Log) != "out\n" {
+					t.Errorf("output = %q, log = %q", out, readFile(t, run.Log))
+				}
+				if tmuxLog != "" {
+					t.Errorf("popup called tmux: %q", tmuxLog)
+				}
+				return
+			}
+			// Windows log through pipe-pane, then stop it
+			if want := "pipe-pane -t %5 cat >> '" + strings.ReplaceAll(run.Log, "'", `'"'"'`) + "'\npipe-pane -t %5\n"; tmuxLog != want {
+				t.Errorf("tmux calls = %q, want %q", tmuxLog, want)
+			}
+		})
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, _ := os.ReadFile(path)
+	return string(data)
+}
@@ -0,0 +1,160 @@
+package launch
+
+import (
+	"fmt"
+	"strings"
+
+	"nunchux/internal/config"
+	"nunchux/internal/items"
+	"nunchux/internal/shell"
+	"nunchux/internal/tmux"
+)
+
+// Pipeline runs a pipeline with action (empty = primary). Parallel
+// pipelines split the task's window into panes, so they never run in a
+// popup.
+func (l *Launcher) Pipeline(p *items.PipelineItem, action config.Action, dir string) error {
+	windowName := p.WindowName()
+	if action == "" {
+		action = p.GetPrimaryAction()
+	}
+	if p.Parallel() && action == config.ActionPopup {
+		action = config.ActionWindow
+	}
+
+	settings := l.Registry.Settings
+	script := sequentialScript(settings, windowName, p.Steps)
+	if p.Parallel() {
+		script = parallelScript(settings, windowName, p.Steps)
+	}
+	hooks := settings.Hooks.Merge(p.Pipeline.Hooks)
+	cmd := tmux.HookScript(script, hooks, p.Name(), action, dir)
+	run, err := l.newRun(p.Name(), "pipeline", p.Pipeline.Name, script, dir, action, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := l.launchTask(windowName, TaskCmd(settings, cmd, windowName, run), dir, action, false); err != nil {
+		return err
+	}
+	l.publish(Event{Type: "launch", Name: p.Name(), Kind: "task", Action: action, Dir: dir})
+	return nil
+}
+
+// pipelineTitle is the window name while a pipeline runs: the steps before
+// current succeeded, current has icon and the rest are still to come
+func pipelineTitle(settings *config.Settings, windowName string, steps []items.PipelineStep, current int, icon string) string {
+	parts := make([]string, len(steps))
+	for i, step := range steps {
+		switch {
+		case i < current:
+			parts[i] = step.Label + " " + settings.TaskrunnerIconSuccess
+		case i == current:
+			parts[i] = step.Label + " " + icon
+		default:
+			parts[i] = step.Label
+		}
+	}
+	return windowName + " [" + strings.Join(parts, " · ") + "]"
+}
+
+// sequentialScript runs the steps one after another in a subshell, which
+// exits with the code of the first step that fails
+func sequentialScript(settings *config.Settings, windowName string, steps []items.PipelineStep) string {
+	var b strings.Builder
+	b.WriteString("(\n")
+	for i, step := range steps {
+		fmt.Fprintf(&b, "tmux rename-window -t \"$TMUX_PANE\" %s 2>/dev/null\n",
+			shell.Quote(pipelineTitle(settings, windowName, steps, i, settings.TaskrunnerIconRunning)))
+		fmt.Fprintf(&b, "echo -e %s\n", shell.Quote(`\033[1m▶ `+step.Label+`\033[0m`))
+		fmt.Fprintf(&b, "(\n%s\n)\nstep_code=$?\n", step.Cmd)
+		fmt.Fprintf(&b, `if [[ $step_code -ne 0 ]]; then
+    tmux rename-window -t "$TMUX_PANE" %s 2>/dev/null
+    exit $step_code
+fi
+echo
+`, shell.Quote(pipelineTitle(settings, windowName, steps, i, settings.TaskrunnerIconFailed)))
+	}
+	b.WriteString(")")
+	return b.String()
+}
+
+// parallelScript runs each step in its own pane of the task's window and
+// waits for all of them, showing each step's status in the window name.
+// The subshell exits with the code of the first step that failed, and
+// removes the status files however it exits.
+func parallelScript(settings *config.Settings, windowName string, steps []items.PipelineStep) string {
+	var b strings.Builder
+	b.WriteString("(\nstatus_dir=$(mktemp -d)\ntrap 'rm -rf \"$status_dir\"' EXIT\n")
+
+	labels := make([]string, len(steps))
+	for i, step := range steps {
+		labels[i] = shell.Quote(step.Label)
+		fmt.Fprintf(&b, "tmux split-window -d -t \"$TMUX_PANE\" -c \"$PWD\" bash -c %s _ \"$status_dir/%d\"\n",
+			shell.Quote(stepPaneScript(settings, step)), i)
+	}
+
+	fmt.Fprintf(&b, `tmux select-layout -t "$TMUX_PANE" tiled >/dev/null 2>&1
+window=%s
+labels=(%s)
+while :; do
+    pending=0
+    title=
+    for i in "${!labels[@]}"; do
+        if [[ ! -f "$status_dir/$i" ]]; then
+            icon=%s
+            pending=1
+        elif [[ $(<"$status_dir/$i") -eq 0 ]]; then
+            icon=%s
+        else
+            icon=%s
+        fi
+        title+=" · ${labels[$i]} $icon"
+    done
+    tmux rename-window -t "$TMUX_PANE" "$window [${title# · }]" 2>/dev/null
+    [[ $pending -eq 0 ]] && break
+    sleep 0.5
+done
+code=0
+for i in "${!labels[@]}"; do
+    step_code=$(<"$status_dir/$i")
+    if [[ $step_code -eq 0 ]]; then
+        echo -e "\033[32m✓ ${labels[$i]}\033[0m"
+    else
+        echo -e "\033[31m✗ ${labels[$i]} failed with exit code $step_code\033[0m"
+        [[ $code -eq 0 ]] && code=$step_code
+    fi
+done
+exit $code
+)`, shell.Quote(windowName), strings.Join(labels, " "),
+		shell.Quote(settings.TaskrunnerIconRunning), shell.Quote(settings.TaskrunnerIconSuccess), shell.Quote(settings.TaskrunnerIconFailed))
+	return b.String()
+}
+
+// stepPaneScript runs one parallel step and writes its exit code to the
+// status file given as $1. The file is renamed into place, so the waiting
+// pane never reads it half-written. A pane killed before the step finishes
+// still reports a failure, or the waiting pane would wait forever.
+func stepPaneScript(settings *config.Settings, step items.PipelineStep) string {
+	return fmt.Sprintf(`status_file=$1
+report() {
+    [[ -f "$status_file" ]] || { echo "$1" > "$status_file.tmp" && mv "$status_file.tmp" "$status_file"; }
+}
+trap 'report 1' EXIT
+source %s 2>/dev/null || true
+echo -e %s
+(
+%s
+)
+step_code=$?
+report "$step_code"
+echo
+if [[ $step_code -eq 0 ]]; then
+    echo -e "\033[32m✓ Step completed successfully\033[0m"
+else
+    echo -e "\033[31m✗ Step failed with exit code $step_code\033[0m"
+fi
+echo "Press any key to close..."
+read -n 1 -s
+`, shell.Quote(settings.BinDir+"/nunchux-run"), shell.Quote(`\033[1m▶ `+step.Label+`\033[0m`), step.Cmd)
+}
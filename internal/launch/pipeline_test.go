@@ -0,0 +1,41 @@
+package launch
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"nunchux/internal/config"
+	"nunchux/internal/items"
+	"nunchux/internal/shell"
+)
+
+func TestStepPaneScript(t *testing.T) {
+	settings := config.DefaultSettings()
+	settings.BinDir = t.TempDir()
+
+	status := filepath.Join(t.TempDir(), "0")
+	runWrapper(t, "bash -c "+shell.Quote(stepPaneScript(&settings, items.PipelineStep{Label: "lint", Cmd: "exit 3"}))+" _ "+shell.Quote(status))
+	if got := readFile(t, status); got != "3\n" {
+		t.Errorf("status = %q, want 3", got)
+	}
+
+	// A pane killed mid-step still reports, so the pipeline stops waiting
+	status = filepath.Join(t.TempDir(), "1")
+	c := exec.Command("bash", "-c", stepPaneScript(&settings, items.PipelineStep{Label: "serve", Cmd: "sleep 30"}), "_", status)
+	if err := c.Start(); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(200 * time.Millisecond)
+	c.Process.Signal(syscall.SIGHUP)
+	c.Wait()
+	if got := readFile(t, status); got != "1\n" {
+		t.Errorf("killed step status = %q, want 1", got)
+	}
+	if _, err := os.Stat(status + ".tmp"); err == nil {
+		t.Error("temporary status file left behind")
+	}
+}
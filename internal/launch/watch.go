@@ -0,0 +1,78 @@
+package launch
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+
+	"nunchux/internal/config"
+	"nunchux/internal/items"
+)
+
+// watch runs a task in a window and starts a `nunchux task watch` process
+// that re-runs it there whenever files in the project change. The watcher
+// exits when the window is closed.
+func (l *Launcher) watch(tr *items.TaskrunnerItem, dir string, args []string) error {
+	settings := l.Registry.Settings
+	windowName := tr.WatchWindowName()
+	if dir == "" {
+		dir = l.Registry.WorkDir()
+	}
+
+	cmd, err := l.taskCmd(tr, config.ActionWatch, windowName, dir, args)
+	if err != nil {
+		return err
+	}
+
+	windowID, _ := l.Tmux.FindWindowByPrefix(windowName)
+	if windowID != "" {
+		// Already watched: run again now
+		if err := l.Tmux.RespawnWindow(windowID, windowName+" "+settings.TaskrunnerIconRunning, dir, cmd); err != nil {
+			return err
+		}
+		l.Tmux.Run("select-window", "-t", windowID)
+	} else {
+		if err := l.launchTask(windowName, cmd, dir, config.ActionWindow, false); err != nil {
+			return err
+		}
+		if windowID, _ = l.Tmux.FindWindowByPrefix(windowName); windowID == "" {
+			return fmt.Errorf("watch: window %q not found after launch", windowName)
+		}
+	}
+
+	// The watcher takes a lock per window, so a second one exits at once
+	if err := startWatcher(windowID, tr.Name(), dir, args); err != nil {
+		return err
+	}
+	l.publish(Event{Type: "launch", Name: tr.Name(), Kind: "task", Action: config.ActionWatch, Dir: dir})
+	return nil
+}
+
+// RespawnWatched re-runs a watched task in its window
+func (l *Launcher) RespawnWatched(tr *items.TaskrunnerItem, windowID, dir string, args []string) error {
+	settings := l.Registry.Settings
+	windowName := tr.WatchWindowName()
+	cmd, err := l.taskCmd(tr, config.ActionWatch, windowName, dir, args)
+	if err != nil {
+		return err
+	}
+	return l.Tmux.RespawnWindow(windowID, windowName+" "+settings.TaskrunnerIconRunning, dir, cmd)
+}
+
+// startWatcher starts a detached `nunchux task watch` for a window
+func startWatcher(windowID, name, dir string, args []string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	cmdArgs := append([]string{"task", "watch", "--window", windowID, "--dir", dir, name, "--"}, args...)
+	cmd := exec.Command(exe, cmdArgs...)
+	cmd.Dir = dir
+	// Own session, so the watcher outlives the menu popup
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	return cmd.Process.Release()
+}
@@ -0,0 +1,9 @@
+// Package shell builds bash command text.
+package shell
+
+import "strings"
+
+// Quote quotes a string for safe use as a single bash word
+func Quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
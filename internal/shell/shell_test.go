@@ -0,0 +1,18 @@
+package shell
+
+import (
+	"os/exec"
+	"testing"
+)
+
+func TestQuote(t *testing.T) {
+	for _, s := range []string{"", "plain", "it's", "a b", "$HOME `id` $(id) \\ \" ; \n"} {
+		out, err := exec.Command("bash", "-c", "printf %s "+Quote(s)).Output()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(out) != s {
+			t.Errorf("Quote(%q) ran as %q", s, out)
+		}
+	}
+}
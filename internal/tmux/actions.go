@@ -10,23 +10,31 @@ import (
 	"nunchux/internal/config"
 )
 
+// Task pane markers
+const (
+	TaskPaneOption = "@nunchux-tasks"    // Pane option set on a window's task pane
+	TaskPaneEnv    = "NUNCHUX_TASK_PANE" // Set in the environment of commands run in it
+)
+
 // LaunchOptions contains options for launching an app/command
 type LaunchOptions struct {
 	Action       config.Action
-	Name         string // Window/popup title
-	Cmd          string // Command to execute
-	Dir          string // Working directory
-	Width        string // Popup width
-	Height       string // Popup height
-	MaxWidth     string // Maximum width (absolute columns)
-	MaxHeight    string // Maximum height (absolute rows)
-	OnExit       string // Command to run after exit (apps only)
-	IsApp        bool   // Whether this is an app (enables error handling)
-	IsTaskrunner bool   // Whether this is a taskrunner command
-	ReuseWindow  bool   // Reuse existing window instead of creating new one
-	RunningIcon  string // Icon to show while running
-	SuccessIcon  string // Icon to show on success
-	FailedIcon   string // Icon to show on failure
+	Name         string       // Window/popup title
+	Cmd          string       // Command to execute
+	Dir          string       // Working directory
+	Width        string       // Popup width
+	Height       string       // Popup height
+	MaxWidth     string       // Maximum width (absolute columns)
+	MaxHeight    string       // Maximum height (absolute rows)
+	Hooks        config.Hooks // Hooks to run around the command
+	IsApp        bool         // Whether this is an app (enables error handling)
+	IsTaskrunner bool         // Whether this is a taskrunner command
+	ReuseWindow  bool         // Reuse existing window instead of creating new one
+	TaskPane     bool         // Run window actions in the window's task pane
+	PaneSize     string       // Height of a new task pane
+	RunningIcon  string       // Icon to show while running
+	SuccessIcon  string       // Icon to show on success
+	FailedIcon   string       // Icon to show on failure
 }
 
 // Launch executes a command with the specified action
@@ -59,6 +67,11 @@ func (c *Client) Launch(opts LaunchOptions) error {
 	// Clamp dimensions to max values if set
 	opts.Width, opts.Height = c.clampDimensions(opts.Width, opts.Height, opts.MaxWidth, opts.MaxHeight)
 
+	// Popups add hooks in their script, around the error handling
+	if opts.Action != config.ActionPopup {
+		opts.Cmd = HookScript(opts.Cmd, opts.Hooks, opts.Name, opts.Action, opts.Dir)
+	}
+
 	switch opts.Action {
 	case config.ActionPopup:
 		return c.launchPopup(opts)
@@ -87,14 +100,22 @@ func (c *Client) launchPopup(opts LaunchOptions) error {
 
 	title := fmt.Sprintf(" nunchux: %s ", opts.Name)
 
+	target := ""
+	if c.target != "" {
+		target = fmt.Sprintf("-t '%s' ", c.target)
+	}
+
 	// Use tmux run-shell with sleep to avoid race condition
-	cmd := fmt.Sprintf("sleep 0.05; tmux display-popup -E -b rounded -T '%s' -w '%s' -h '%s' '%s'",
-		title, opts.Width, opts.Height, script)
+	cmd := fmt.Sprintf("sleep 0.05; tmux display-popup %s-E -b rounded -T '%s' -w '%s' -h '%s' '%s'",
+		target, title, opts.Width, opts.Height, script)
 
 	return exec.Command("tmux", "run-shell", "-b", cmd).Run()
 }
 
 func (c *Client) launchWindow(opts LaunchOptions, background bool) error {
+	if opts.TaskPane {
+		return c.launchTaskPane(opts, background)
+	}
 	// For taskrunners with existing window, use respawn-window
 	if opts.IsTaskrunner && opts.ReuseWindow {
 		return c.respawnTaskrunnerWindow(opts, background)
@@ -106,6 +127,9 @@ func (c *Client) launchWindow(opts LaunchOptions, background bool) error {
 	}
 
 	args := []string{"new-window", "-n", windowName}
+	if c.target != "" {
+		args = append(args, "-t", c.sessionTarget(""))
+	}
 	if opts.Dir != "" {
 		args = append(args, "-c", opts.Dir)
 	}
@@ -124,7 +148,7 @@ func (c *Client) launchWindow(opts LaunchOptions, background bool) error {
 
 func (c *Client) respawnTaskrunnerWindow(opts LaunchOptions, background bool) error {
 	// Find existing window by name prefix (might have icon suffix)
-	windowID, err := c.findWindowByPrefix(opts.Name)
+	windowID, err := c.FindWindowByPrefix(opts.Name)
 	if err != nil || windowID == "" {
 		// No existing window, create new one
 		return c.launchWindow(LaunchOptions{
@@ -147,11 +171,7 @@ func (c *Client) respawnTaskrunnerWindow(opts LaunchOptions, background bool) er
 	if opts.RunningIcon != "" {
 		windowName = opts.Name + " " + opts.RunningIcon
 	}
-
-	// Rename and respawn existing window
-	exec.Command("tmux", "rename-window", "-t", windowID, windowName).Run()
-	err = exec.Command("tmux", "respawn-window", "-k", "-t", windowID, "-c", opts.Dir, c.WrapCommand(opts.Cmd)).Run()
-	if err != nil {
+	if err := c.RespawnWindow(windowID, windowName, opts.Dir, opts.Cmd); err != nil {
 		return err
 	}
 
@@ -165,8 +185,36 @@ func (c *Client) respawnTaskrunnerWindow(opts LaunchOptions, background bool) er
 	return nil
 }
 
-func (c *Client) findWindowByPrefix(prefix string) (string, error) {
-	output, err := exec.Command("tmux", "list-windows", "-F", "#{window_id} #{window_name}").Output()
+// RespawnWindow renames a window and restarts it with cmd, killing the
+// command it was running
+func (c *Client) RespawnWindow(windowID, name, dir, cmd string) error {
+	exec.Command("tmux", "rename-window", "-t", windowID, name).Run()
+	args := []string{"respawn-window", "-k", "-t", windowID}
+	if dir != "" {
+		args = append(args, "-c", dir)
+	}
+	return exec.Command("tmux", append(args, c.WrapCommand(cmd))...).Run()
+}
+
+// WindowExists reports whether the window with the given ID still exists
+func (c *Client) WindowExists(windowID string) bool {
+	output, err := exec.Command("tmux", "list-windows", "-a", "-F", "#{window_id}").Output()
+	if err != nil {
+		return false
+	}
+	for _, id := range strings.Fields(string(output)) {
+		if id == windowID {
+			return true
+		}
+	}
+	return false
+}
+
+// FindWindowByPrefix returns the ID of the first window whose name starts
+// with prefix, or "" if there is none
+func (c *Client) FindWindowByPrefix(prefix string) (string, error) {
+	args := append([]string{"list-windows"}, c.targetArgs()...)
+	output, err := exec.Command("tmux", append(args, "-F", "#{window_id} #{window_name}")...).Output()
 	if err != nil {
 		return "", err
 	}
@@ -187,7 +235,7 @@ func (c *Client) findWindowByPrefix(prefix string) (string, error) {
 }
 
 func (c *Client) launchPane(opts LaunchOptions, direction string, before bool) error {
-	args := []string{"split-window", direction}
+	args := append([]string{"split-window", direction}, c.targetArgs()...)
 	if before {
 		args = append(args, "-b")
 	}
@@ -196,22 +244,66 @@ func (c *Client) launchPane(opts LaunchOptions, direction string, before bool) e
 	return exec.Command("tmux", args...).Run()
 }
 
+// launchTaskPane runs a task in the window's task pane, respawning it if it
+// exists and splitting it below the current pane otherwise
+func (c *Client) launchTaskPane(opts LaunchOptions, background bool) error {
+	paneID := c.FindTaskPane()
+	var args []string
+	if paneID != "" {
+		args = []string{"respawn-pane", "-k", "-t", paneID}
+	} else {
+		args = append([]string{"split-window", "-v", "-d", "-P", "-F", "#{pane_id}"}, c.targetArgs()...)
+		if opts.PaneSize != "" {
+			args = append(args, "-l", opts.PaneSize)
+		}
+	}
+	// The task wrapper sets the pane title instead of the window name
+	args = append(args, "-e", TaskPaneEnv+"=1", "-c", opts.Dir, c.WrapCommand(opts.Cmd))
+
+	output, err := exec.Command("tmux", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s failed: %v: %s (args: %v)", args[0], err, string(output), args)
+	}
+	if paneID == "" {
+		paneID = strings.TrimSpace(string(output))
+		if err := exec.Command("tmux", "set-option", "-p", "-t", paneID, TaskPaneOption, "1").Run(); err != nil {
+			return err
+		}
+	}
+
+	if !background {
+		return exec.Command("tmux", "select-pane", "-t", paneID).Run()
+	}
+	return nil
+}
+
+// FindTaskPane returns the ID of the task pane in the current window, or ""
+// if it has none
+func (c *Client) FindTaskPane() string {
+	args := append([]string{"list-panes"}, c.targetArgs()...)
+	output, err := exec.Command("tmux", append(args, "-F", "#{pane_id} #{"+TaskPaneOption+"}")...).Output()
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if id, mark, ok := strings.Cut(line, " "); ok && mark == "1" {
+			return id
+		}
+	}
+	return ""
+}
+
 func (c *Client) createPopupScript(opts LaunchOptions) (string, error) {
 	script := filepath.Join(os.TempDir(), fmt.Sprintf("nunchux-popup-%d", os.Getpid()))
 
-	// Substitute variables in cmd and on_exit
+	// Substitute variables in cmd and hooks
 	paneID, _ := c.GetPaneID()
 	tmpFile := filepath.Join(os.TempDir(), fmt.Sprintf("nunchux-tmp-%d", os.Getpid()))
 
-	cmd := opts.Cmd
-	cmd = strings.ReplaceAll(cmd, "{pane_id}", paneID)
-	cmd = strings.ReplaceAll(cmd, "{tmp}", tmpFile)
-	cmd = strings.ReplaceAll(cmd, "{dir}", opts.Dir)
-
-	onExit := opts.OnExit
-	onExit = strings.ReplaceAll(onExit, "{pane_id}", paneID)
-	onExit = strings.ReplaceAll(onExit, "{tmp}", tmpFile)
-	onExit = strings.ReplaceAll(onExit, "{dir}", opts.Dir)
+	replacer := strings.NewReplacer("{pane_id}", paneID, "{tmp}", tmpFile, "{dir}", opts.Dir)
+	cmd := replacer.Replace(opts.Cmd)
+	// HookScript substitutes {dir} in hooks itself, as a variable
+	hooks := substituteHooks(opts.Hooks, strings.NewReplacer("{pane_id}", paneID, "{tmp}", tmpFile))
 
 	var content strings.Builder
 	content.WriteString("#!/usr/bin/env bash\n")
@@ -226,14 +318,12 @@ func (c *Client) createPopupScript(opts LaunchOptions) (string, error) {
 
 	if opts.IsApp {
 		// App popup: error handling with Chuck Norris facts
-		content.WriteString("# Run the command (suppress stderr, we show our own error)\n")
-		content.WriteString(fmt.Sprintf("%s 2>/dev/null\n", cmd))
-		content.WriteString("exit_code=$?\n\n")
-
-		if onExit != "" {
-			content.WriteString("# Run on_exit if defined\n")
-			content.WriteString(onExit + "\n\n")
+		content.WriteString("# Run the command (suppress stderr, we show our own error) and hooks\n")
+		content.WriteString(HookScript(cmd+" 2>/dev/null", hooks, opts.Name, opts.Action, opts.Dir))
+		if hooks.IsEmpty() {
+			content.WriteString("\nexit_code=$?\n")
 		}
+		content.WriteString("\n")
 
 		content.WriteString("# If command failed, show error with Chuck Norris fact\n")
 		content.WriteString("if [[ $exit_code -ne 0 ]]; then\n")
@@ -253,7 +343,7 @@ func (c *Client) createPopupScript(opts LaunchOptions) (string, error) {
 		content.WriteString("fi\n")
 	} else {
 		// Simple popup (dirbrowser, etc.)
-		content.WriteString(cmd + "\n")
+		content.WriteString(HookScript(cmd, hooks, opts.Name, opts.Action, opts.Dir) + "\n")
 	}
 
 	content.WriteString(fmt.Sprintf("rm -f \"%s\"\n", script))
@@ -310,11 +400,12 @@ func (c *Client) clampDimensions(width, height, maxWidth, maxHeight string) (str
 
 // getTerminalSize returns the terminal width and height in columns/rows
 func (c *Client) getTerminalSize() (int, int) {
-	widthOut, err := exec.Command("tmux", "display-message", "-p", "#{window_width}").Output()
+	args := append([]string{"display-message", "-p"}, c.targetArgs()...)
+	widthOut, err := exec.Command("tmux", append(args, "#{window_width}")...).Output()
 	if err != nil {
 		return 0, 0
 	}
-	heightOut, err := exec.Command("tmux", "display-message", "-p", "#{window_height}").Output()
+	heightOut, err := exec.Command("tmux", append(args, "#{window_height}")...).Output()
 	if err != nil {
 		return 0, 0
 	}
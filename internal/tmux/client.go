@@ -1,14 +1,18 @@
 package tmux
 
 import (
+	"fmt"
 	"os"
 	"os/exec"
 	"strings"
+
+	"nunchux/internal/shell"
 )
 
 // Client handles tmux command execution
 type Client struct {
 	binDir string // Path to nunchux bin directory for nunchux-run wrapper
+	target string // Pane commands are relative to (empty = current pane)
 }
 
 // NewClient creates a new tmux client
@@ -16,6 +20,32 @@ func NewClient(binDir string) *Client {
 	return &Client{binDir: binDir}
 }
 
+// WithTarget returns a client whose commands act relative to a pane instead
+// of the current one. Used when nunchux runs outside tmux (daemon, ctl).
+func (c *Client) WithTarget(pane string) *Client {
+	return &Client{binDir: c.binDir, target: pane}
+}
+
+// targetArgs returns "-t <pane>" when the client has a target pane
+func (c *Client) targetArgs() []string {
+	if c.target == "" {
+		return nil
+	}
+	return []string{"-t", c.target}
+}
+
+// sessionTarget qualifies a window name with the target pane's session
+func (c *Client) sessionTarget(window string) string {
+	if c.target == "" {
+		return window
+	}
+	session, err := c.RunOutput("display-message", "-p", "-t", c.target, "#{session_id}")
+	if err != nil || session == "" {
+		return window
+	}
+	return session + ":" + window
+}
+
 // InSession checks if we're running inside a tmux session
 func InSession() bool {
 	return os.Getenv("TMUX") != ""
@@ -29,7 +59,8 @@ func IsAvailable() bool {
 
 // ListWindows returns list of window names in the current session
 func (c *Client) ListWindows() ([]string, error) {
-	output, err := exec.Command("tmux", "list-windows", "-F", "#{window_name}").Output()
+	args := append([]string{"list-windows"}, c.targetArgs()...)
+	output, err := exec.Command("tmux", append(args, "-F", "#{window_name}")...).Output()
 	if err != nil {
 		return nil, err
 	}
@@ -40,7 +71,9 @@ func (c *Client) ListWindows() ([]string, error) {
 	return lines, nil
 }
 
-// RunningWindows returns a map of running window names for efficient lookup
+// RunningWindows returns a map of running window names for efficient lookup.
+// Titles of task panes count as window names, since they show the task the
+// same way.
 func (c *Client) RunningWindows() map[string]bool {
 	windows, err := c.ListWindows()
 	if err != nil {
@@ -50,6 +83,15 @@ func (c *Client) RunningWindows() map[string]bool {
 	for _, w := range windows {
 		result[w] = true
 	}
+	args := append([]string{"list-panes", "-s"}, c.targetArgs()...)
+	output, err := exec.Command("tmux", append(args, "-F", "#{"+TaskPaneOption+"} #{pane_title}")...).Output()
+	if err == nil {
+		for _, line := range strings.Split(string(output), "\n") {
+			if title, ok := strings.CutPrefix(line, "1 "); ok {
+				result[title] = true
+			}
+		}
+	}
 	return result
 }
 
@@ -69,7 +111,8 @@ func (c *Client) IsWindowRunning(name string) bool {
 
 // GetCurrentPath returns the current pane's working directory
 func (c *Client) GetCurrentPath() (string, error) {
-	output, err := exec.Command("tmux", "display-message", "-p", "#{pane_current_path}").Output()
+	args := append([]string{"display-message", "-p"}, c.targetArgs()...)
+	output, err := exec.Command("tmux", append(args, "#{pane_current_path}")...).Output()
 	if err != nil {
 		return "", err
 	}
@@ -78,6 +121,9 @@ func (c *Client) GetCurrentPath() (string, error) {
 
 // GetPaneID returns the current pane ID
 func (c *Client) GetPaneID() (string, error) {
+	if c.target != "" {
+		return c.target, nil
+	}
 	output, err := exec.Command("tmux", "display-message", "-p", "#{pane_id}").Output()
 	if err != nil {
 		return "", err
@@ -87,12 +133,25 @@ func (c *Client) GetPaneID() (string, error) {
 
 // SelectWindow switches to a window by name
 func (c *Client) SelectWindow(name string) error {
-	return exec.Command("tmux", "select-window", "-t", name).Run()
+	return exec.Command("tmux", "select-window", "-t", c.sessionTarget(name)).Run()
 }
 
 // KillWindow kills a window by name
 func (c *Client) KillWindow(name string) error {
-	return exec.Command("tmux", "kill-window", "-t", name).Run()
+	return exec.Command("tmux", "kill-window", "-t", c.sessionTarget(name)).Run()
+}
+
+// KillWindowByPrefix kills the first window whose name starts with prefix.
+// Task windows carry a status icon after their name.
+func (c *Client) KillWindowByPrefix(prefix string) error {
+	windowID, err := c.FindWindowByPrefix(prefix)
+	if err != nil {
+		return err
+	}
+	if windowID == "" {
+		return fmt.Errorf("no window named %q", prefix)
+	}
+	return exec.Command("tmux", "kill-window", "-t", windowID).Run()
 }
 
 // Run executes a tmux command
@@ -112,13 +171,7 @@ func (c *Client) RunOutput(args ...string) (string, error) {
 // WrapCommand wraps a command with the nunchux-run environment wrapper
 func (c *Client) WrapCommand(cmd string) string {
 	if c.binDir != "" {
-		return c.binDir + "/nunchux-run bash -c " + shellQuote(cmd)
+		return c.binDir + "/nunchux-run bash -c " + shell.Quote(cmd)
 	}
-	return "bash -c " + shellQuote(cmd)
-}
-
-// shellQuote quotes a string for safe shell usage
-func shellQuote(s string) string {
-	// Use single quotes and escape any single quotes in the string
-	return "'" + strings.ReplaceAll(s, "'", "'\"'\"'") + "'"
+	return "bash -c " + shell.Quote(cmd)
 }
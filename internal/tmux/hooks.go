@@ -0,0 +1,75 @@
+package tmux
+
+import (
+	"fmt"
+	"strings"
+
+	"nunchux/internal/config"
+	"nunchux/internal/shell"
+)
+
+// HookScript wraps cmd with hooks. The script leaves the command's exit code
+// in $exit_code and in $?, and is cmd unchanged when there are no hooks.
+func HookScript(cmd string, hooks config.Hooks, name string, action config.Action, dir string) string {
+	if hooks.IsEmpty() {
+		return cmd
+	}
+	hooks = substituteHooks(hooks, hookReplacer)
+
+	var script strings.Builder
+	script.WriteString(hookEnv(name, action, dir))
+	if hooks.PreLaunch != "" {
+		script.WriteString(hooks.PreLaunch + "\n")
+	}
+	script.WriteString(cmd + "\n")
+	script.WriteString("exit_code=$?\n")
+	if hooks.OnSuccess != "" || hooks.OnFail != "" {
+		script.WriteString("if [[ $exit_code -eq 0 ]]; then\n")
+		script.WriteString(orNoop(hooks.OnSuccess) + "\n")
+		script.WriteString("else\n")
+		script.WriteString(orNoop(hooks.OnFail) + "\n")
+		script.WriteString("fi\n")
+	}
+	if hooks.OnExit != "" {
+		script.WriteString(hooks.OnExit + "\n")
+	}
+	script.WriteString("(exit $exit_code)\n")
+	return script.String()
+}
+
+// hookReplacer turns hook variables into references to the variables
+// hookEnv exports, so names and directories are never parsed as shell code
+var hookReplacer = strings.NewReplacer(
+	"{exit_code}", "$exit_code",
+	"{name}", "${NUNCHUX_NAME}",
+	"{action}", "${NUNCHUX_ACTION}",
+	"{dir}", "${NUNCHUX_DIR}",
+)
+
+// hookEnv exports the values of hook variables. The directory falls back to
+// the shell's working directory when the launch directory is not known yet.
+func hookEnv(name string, action config.Action, dir string) string {
+	dirValue := `"$PWD"`
+	if dir != "" {
+		dirValue = shell.Quote(dir)
+	}
+	return fmt.Sprintf("export NUNCHUX_NAME=%s NUNCHUX_ACTION=%s NUNCHUX_DIR=%s\n",
+		shell.Quote(name), shell.Quote(string(action)), dirValue)
+}
+
+func substituteHooks(h config.Hooks, r *strings.Replacer) config.Hooks {
+	return config.Hooks{
+		PreLaunch: r.Replace(h.PreLaunch),
+		OnSuccess: r.Replace(h.OnSuccess),
+		OnFail:    r.Replace(h.OnFail),
+		OnExit:    r.Replace(h.OnExit),
+	}
+}
+
+// orNoop returns cmd, or the no-op command if it is empty
+func orNoop(cmd string) string {
+	if cmd == "" {
+		return ":"
+	}
+	return cmd
+}
@@ -0,0 +1,61 @@
+package tmux
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"nunchux/internal/config"
+)
+
+func runScript(t *testing.T, script string) string {
+	t.Helper()
+	out, _ := exec.Command("bash", "-c", script+"\necho status=$?").CombinedOutput()
+	return strings.TrimSpace(string(out))
+}
+
+func TestHookScriptNoHooks(t *testing.T) {
+	if got := HookScript("lazygit", config.Hooks{}, "lazygit", config.ActionPopup, "/tmp"); got != "lazygit" {
+		t.Errorf("HookScript without hooks = %q, want cmd unchanged", got)
+	}
+}
+
+func TestHookScript(t *testing.T) {
+	global := config.Hooks{PreLaunch: "echo pre {name} {action} {dir}", OnExit: "echo global-exit {exit_code}"}
+	item := config.Hooks{OnSuccess: "echo ok", OnFail: `echo "fail {name}"`, OnExit: "echo item-exit"}
+	hooks := global.Merge(item)
+
+	tests := []struct {
+		cmd  string
+		want string
+	}{
+		{"true", "pre build window /src\nok\nglobal-exit 0\nitem-exit\nstatus=0"},
+		{"exit 3", "pre build window /src\nfail build\nglobal-exit 3\nitem-exit\nstatus=3"},
+	}
+	for _, tt := range tests {
+		// Run the command in a subshell so "exit" does not end the script
+		got := runScript(t, HookScript("("+tt.cmd+")", hooks, "build", config.ActionWindow, "/src"))
+		if got != tt.want {
+			t.Errorf("HookScript(%q) output:\n%s\nwant:\n%s", tt.cmd, got, tt.want)
+		}
+	}
+}
+
+func TestHookScriptQuoting(t *testing.T) {
+	hooks := config.Hooks{
+		PreLaunch: `printf '%s|' "{name}" "{dir}"`,
+		OnExit:    `echo "{action}"`,
+	}
+	name := `it's $HOME; echo pwned`
+	dir := "/tmp/a b/`id`"
+	got := runScript(t, HookScript("true", hooks, name, config.ActionWindow, dir))
+	if want := name + "|" + dir + "|window\nstatus=0"; got != want {
+		t.Errorf("output:\n%s\nwant:\n%s", got, want)
+	}
+
+	// Without a launch directory, {dir} is the shell's working directory
+	got = runScript(t, "cd /tmp\n"+HookScript("true", config.Hooks{OnExit: `echo "{dir}"`}, "x", config.ActionPopup, ""))
+	if got != "/tmp\nstatus=0" {
+		t.Errorf("{dir} fallback output = %q", got)
+	}
+}
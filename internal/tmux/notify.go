@@ -0,0 +1,55 @@
+package tmux
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// Notification modes for finished tasks
+const (
+	NotifyTmux    = "tmux"    // tmux display-message on the client
+	NotifyBell    = "bell"    // Ring the bell on the client's terminal
+	NotifyDesktop = "desktop" // notify-send
+	NotifyNone    = "none"
+)
+
+// Notify shows message with the given mode. client is the tty of the tmux
+// client to notify (empty = tmux's choice for the current session).
+func Notify(mode, client, title, message string) error {
+	switch mode {
+	case NotifyTmux:
+		args := []string{"display-message"}
+		if client != "" {
+			args = append(args, "-c", client)
+		}
+		return exec.Command("tmux", append(args, title+": "+message)...).Run()
+
+	case NotifyBell:
+		if client == "" {
+			return fmt.Errorf("no client to ring the bell on")
+		}
+		tty, err := os.OpenFile(client, os.O_WRONLY, 0)
+		if err != nil {
+			return err
+		}
+		defer tty.Close()
+		_, err = tty.WriteString("\a")
+		return err
+
+	case NotifyDesktop:
+		return exec.Command("notify-send", title, message).Run()
+
+	case NotifyNone, "":
+		return nil
+	}
+	return fmt.Errorf("unknown notify mode %q", mode)
+}
+
+// ClientTTY returns the tty of the tmux client attached to the target
+// pane's session
+func (c *Client) ClientTTY() string {
+	args := append([]string{"display-message", "-p"}, c.targetArgs()...)
+	tty, _ := c.RunOutput(append(args, "#{client_tty}")...)
+	return tty
+}
@@ -0,0 +1,33 @@
+package tmux
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNotifyBell(t *testing.T) {
+	client := filepath.Join(t.TempDir(), "tty")
+	os.WriteFile(client, nil, 0644)
+
+	if err := Notify(NotifyBell, client, "nunchux", "just:build succeeded in 1.2s"); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(client); string(data) != "\a" {
+		t.Errorf("tty got %q, want a bell", data)
+	}
+	if err := Notify(NotifyBell, "", "nunchux", "done"); err == nil {
+		t.Error("bell without a client should fail")
+	}
+}
+
+func TestNotifyModes(t *testing.T) {
+	for _, mode := range []string{NotifyNone, ""} {
+		if err := Notify(mode, "", "nunchux", "done"); err != nil {
+			t.Errorf("Notify(%q) = %v", mode, err)
+		}
+	}
+	if err := Notify("email", "", "nunchux", "done"); err == nil {
+		t.Error("unknown mode should fail")
+	}
+}
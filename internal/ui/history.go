@@ -0,0 +1,72 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"nunchux/internal/config"
+	"nunchux/internal/fzf"
+	"nunchux/internal/history"
+)
+
+// historyRerunKey re-runs the highlighted run in the task history view
+const historyRerunKey = "ctrl-r"
+
+// HistorySelection is a run picked in the task history view
+type HistorySelection struct {
+	Run   history.Run
+	Rerun bool // Re-run the command instead of opening the log
+}
+
+// ShowTaskHistory lists past task runs, newest first. It returns nil if the
+// user cancels.
+func ShowTaskHistory(settings *config.Settings, runs []history.Run) (*HistorySelection, error) {
+	if len(runs) == 0 {
+		return nil, fmt.Errorf("no task runs recorded yet")
+	}
+
+	nameWidth, dirWidth := 0, 0
+	dirs := make([]string, len(runs))
+	home, _ := os.UserHomeDir()
+	for i, run := range runs {
+		dirs[i] = run.Dir
+		if home != "" && strings.HasPrefix(run.Dir, home) {
+			dirs[i] = "~" + run.Dir[len(home):]
+		}
+		nameWidth = max(nameWidth, len([]rune(run.Name)))
+		dirWidth = max(dirWidth, len([]rune(dirs[i])))
+	}
+
+	var lines []string
+	for i, run := range runs {
+		icon := settings.TaskrunnerIconSuccess
+		if !run.Succeeded() {
+			icon = settings.TaskrunnerIconFailed
+		}
+		display := fmt.Sprintf("%s %-*s  %-*s  %8s  %s",
+			icon, nameWidth, run.Name, dirWidth, dirs[i],
+			history.FormatDuration(run.Duration), history.Ago(run.Start))
+		if !run.Succeeded() {
+			display += fmt.Sprintf("  (exit %d)", run.ExitCode)
+		}
+		lines = append(lines, display+"\t"+strconv.Itoa(i))
+	}
+
+	header := "enter: open log │ " + historyRerunKey + ": re-run │ esc: back"
+	opts := fzf.BuildForHistory(settings, header, historyRerunKey)
+	sel, err := fzf.Run(strings.Join(lines, "\n"), opts)
+	if err != nil {
+		return nil, err
+	}
+	if sel.Canceled || sel.Key == "esc" || len(sel.Fields) < 2 {
+		return nil, nil
+	}
+
+	i, err := strconv.Atoi(sel.Fields[1])
+	if err != nil || i < 0 || i >= len(runs) {
+		return nil, nil
+	}
+	return &HistorySelection{Run: runs[i], Rerun: sel.Key == historyRerunKey}, nil
+}
@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 
 	"nunchux/internal/config"
+	"nunchux/internal/daemon"
 	"nunchux/internal/fzf"
 	"nunchux/internal/items"
 	"nunchux/internal/tmux"
@@ -20,15 +22,14 @@ type Selection struct {
 	Action   config.Action // Resolved action based on key
 	Canceled bool          // True if user canceled (Ctrl-C)
 	Back     bool          // True if user pressed Esc
+	History  bool          // True if user pressed the task history key
+	Rerun    bool          // True if user pressed the rerun key
 }
 
 // ShowMenu displays the fzf menu and returns the selection
 func ShowMenu(ctx context.Context, registry *items.Registry, tmuxClient *tmux.Client, currentMenu string) (*Selection, error) {
-	// Get running windows for display
-	runningWindows := tmuxClient.RunningWindows()
-
 	// Build menu content
-	menuContent := registry.BuildMenu(ctx, runningWindows, currentMenu)
+	menuContent := MenuContent(ctx, registry, tmuxClient, currentMenu)
 
 	// If no items, show empty config fallback menu
 	if menuContent == "" && currentMenu == "" {
@@ -56,6 +57,12 @@ func ShowMenu(ctx context.Context, registry *items.Registry, tmuxClient *tmux.Cl
 	if sel.Key == "esc" {
 		return &Selection{Back: true}, nil
 	}
+	if sel.Key != "" && sel.Key == registry.Settings.TaskHistoryKey {
+		return &Selection{History: true}, nil
+	}
+	if sel.Key != "" && sel.Key == registry.Settings.RerunKey {
+		return &Selection{Rerun: true}, nil
+	}
 
 	// Extract name from fzf output (fields: display, shortcut, name)
 	if len(sel.Fields) < 3 {
@@ -64,6 +71,12 @@ func ShowMenu(ctx context.Context, registry *items.Registry, tmuxClient *tmux.Cl
 
 	name := sel.Fields[2]
 
+	// Menu content may have come from the daemon; load taskrunners locally
+	// so the selected task can be found
+	if name != "" && registry.FindItem(name) == nil {
+		registry.EnsureTaskrunners(ctx)
+	}
+
 	// Resolve action using item-specific settings
 	action := resolveActionForItem(sel.Key, name, registry)
 
@@ -74,6 +87,33 @@ func ShowMenu(ctx context.Context, registry *items.Registry, tmuxClient *tmux.Cl
 	}, nil
 }
 
+// MenuContent returns the menu content for fzf. A running daemon serves
+// pre-rendered content; otherwise the menu is built in-process.
+func MenuContent(ctx context.Context, registry *items.Registry, tmuxClient *tmux.Client, currentMenu string) string {
+	runningWindows := tmuxClient.RunningWindows()
+
+	if registry.ConfigPath != "" {
+		configPath, _ := filepath.Abs(registry.ConfigPath)
+		var running []string
+		for name := range runningWindows {
+			running = append(running, name)
+		}
+		content, err := daemon.RenderMenu(daemon.MenuParams{
+			Config:   configPath,
+			Dir:      registry.WorkDir(),
+			Submenu:  currentMenu,
+			ShowHelp: registry.Settings.ShowHelp,
+			Running:  running,
+		})
+		if err == nil {
+			return content
+		}
+	}
+
+	registry.EnsureTaskrunners(ctx)
+	return registry.BuildMenu(ctx, runningWindows, currentMenu)
+}
+
 // getPaneCurrentPath returns the tmux pane's current working directory
 func getPaneCurrentPath() string {
 	output, err := exec.Command("tmux", "display-message", "-p", "#{pane_current_path}").Output()
@@ -89,7 +129,9 @@ func buildFzfOptions(settings *config.Settings, currentMenu string, shortcuts ma
 
 	// Build border label
 	label := " " + settings.Label
-	if currentMenu != "" {
+	if runner, ok := strings.CutPrefix(currentMenu, items.TaskMenuPrefix); ok {
+		label += ": " + runner + " tasks"
+	} else if currentMenu != "" {
 		label += ": " + currentMenu
 	}
 	if settings.ShowCwd {
@@ -113,12 +155,20 @@ func buildFzfOptions(settings *config.Settings, currentMenu string, shortcuts ma
 		if settings.ActionMenuKey != "" {
 			header.WriteString(settings.ActionMenuKey + ": action menu │ ")
 		}
+		if settings.TaskHistoryKey != "" {
+			header.WriteString(settings.TaskHistoryKey + ": history │ ")
+		}
+		if settings.RerunKey != "" {
+			header.WriteString(settings.RerunKey + ": rerun │ ")
+		}
 		header.WriteString("esc: back")
 	}
 	if header.Len() > 0 {
 		builder.Header(header.String())
 	}
 
+	builder.ExpectKeys(settings.TaskHistoryKey, settings.WatchKey, settings.RerunKey)
+
 	exe, _ := os.Executable()
 
 	// Add toggle shortcuts keybinding
@@ -185,7 +235,17 @@ func resolveActionForItem(key string, name string, registry *items.Registry) con
 		// Try taskrunner items
 		if trItem := registry.FindTaskrunnerItem(name); trItem != nil {
 			item = trItem
+		} else if pipeline := registry.FindPipeline(name); pipeline != nil {
+			item = pipeline
+		}
+	}
+
+	// The watch key only applies to tasks
+	if key != "" && key == settings.WatchKey {
+		if _, ok := item.(*items.TaskrunnerItem); ok {
+			return config.ActionWatch
 		}
+		key = ""
 	}
 
 	// Use item-specific actions if available
@@ -241,12 +301,27 @@ func showEmptyConfigMenu(settings *config.Settings) (*Selection, error) {
 	}, nil
 }
 
+// actionChoice is an entry of the action menu
+type actionChoice struct {
+	id   config.Action
+	name string
+}
+
 // ShowActionMenu displays the action selection menu
 func ShowActionMenu(settings *config.Settings, itemName string) (config.Action, error) {
-	actions := []struct {
-		id   config.Action
-		name string
-	}{
+	return showActionMenu(settings, itemName, nil)
+}
+
+// ShowTaskActionMenu displays the action selection menu for a task, which
+// can also be watched
+func ShowTaskActionMenu(settings *config.Settings, itemName string) (config.Action, error) {
+	return showActionMenu(settings, itemName, []actionChoice{
+		{config.ActionWatch, "Watch: re-run when files change"},
+	})
+}
+
+func showActionMenu(settings *config.Settings, itemName string, extra []actionChoice) (config.Action, error) {
+	actions := []actionChoice{
 		{config.ActionPopup, "Open in popup"},
 		{config.ActionWindow, "Open in window"},
 		{config.ActionBackgroundWindow, "Open in background window"},
@@ -257,7 +332,7 @@ func ShowActionMenu(settings *config.Settings, itemName string) (config.Action,
 	}
 
 	var lines []string
-	for _, a := range actions {
+	for _, a := range append(actions, extra...) {
 		lines = append(lines, fmt.Sprintf("%s\t%s", a.id, a.name))
 	}
 
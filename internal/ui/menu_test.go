@@ -0,0 +1,57 @@
+package ui
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"nunchux/internal/config"
+	"nunchux/internal/items"
+	"nunchux/internal/tmux"
+)
+
+func TestMenuContentFallback(t *testing.T) {
+	dir := t.TempDir()
+	socket := filepath.Join(dir, "d.sock")
+	t.Setenv("NUNCHUX_SOCKET", socket)
+	t.Setenv("NUNCHUX_NO_DAEMON", "")
+	t.Setenv("TMUX", "")
+
+	// A hung daemon: accepts and never answers
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	configPath := filepath.Join(dir, "config")
+	if err := os.WriteFile(configPath, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{Path: configPath, Settings: config.DefaultSettings()}
+	cfg.Apps = []config.App{{Name: "htop", Cmd: "htop"}}
+	registry := items.NewRegistry(cfg)
+	defer registry.Close()
+
+	start := time.Now()
+	content := MenuContent(context.Background(), registry, tmux.NewClient(""), "")
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("MenuContent took %v with a hung daemon", elapsed)
+	}
+	if !strings.Contains(content, "\thtop") {
+		t.Errorf("in-process menu missing htop:\n%s", content)
+	}
+}
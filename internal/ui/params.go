@@ -0,0 +1,57 @@
+package ui
+
+import (
+	"fmt"
+
+	"nunchux/internal/config"
+	"nunchux/internal/fzf"
+	"nunchux/internal/items"
+)
+
+// PromptTaskParams asks for a value for each of a task's parameters, one
+// prompt at a time, starting from the remembered values or the defaults.
+// It returns false if the user cancels.
+func PromptTaskParams(settings *config.Settings, tr *items.TaskrunnerItem, remembered []string) ([]string, bool, error) {
+	params := tr.Task.Params
+	values := make([]string, len(params))
+
+	for i, param := range params {
+		query := param.Default
+		if i < len(remembered) {
+			query = remembered[i]
+		}
+
+		header := "Enter to continue, Esc to cancel"
+		if param.Optional {
+			header = "Optional; leave empty to skip. " + header
+		}
+		if param.Default != "" {
+			header = fmt.Sprintf("Default: %s. %s", param.Default, header)
+		}
+
+		label := fmt.Sprintf("%s (%d/%d)", tr.WindowName(), i+1, len(params))
+		opts := fzf.BuildForInput(settings, label, param.Name+"> ", query, header)
+		value, ok, err := fzf.Input(opts)
+		if err != nil || !ok {
+			return nil, false, err
+		}
+		values[i] = value
+	}
+	return values, true, nil
+}
+
+// ConfirmTask asks whether to run a task with the given arguments. It
+// returns false if the user declines or cancels.
+func ConfirmTask(settings *config.Settings, tr *items.TaskrunnerItem, args []string) (bool, error) {
+	cmd, err := tr.Task.Command(args)
+	if err != nil {
+		return false, err
+	}
+	lines := "no\tNo, cancel\nyes\tYes, run " + cmd
+	opts := fzf.BuildForConfirm(settings, "Run "+tr.WindowName()+"?")
+	sel, err := fzf.Run(lines, opts)
+	if err != nil || sel.Canceled || len(sel.Fields) == 0 {
+		return false, err
+	}
+	return sel.Fields[0] == "yes", nil
+}
@@ -0,0 +1,188 @@
+//go:build linux
+
+package watch
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sync"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// inotifyMask is the set of events that count as a change
+const inotifyMask = unix.IN_CREATE | unix.IN_CLOSE_WRITE | unix.IN_DELETE |
+	unix.IN_MOVED_FROM | unix.IN_MOVED_TO | unix.IN_ATTRIB | unix.IN_MODIFY
+
+// inotify watches every directory of the tree. New directories are added
+// as they appear.
+type inotify struct {
+	fd   int
+	root string
+	skip SkipFunc
+	ch   chan string
+
+	mu   sync.Mutex
+	dirs map[int]string // Watch descriptor -> directory
+	done chan struct{}
+}
+
+func newNative(root string, skip SkipFunc) (backend, error) {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return nil, err
+	}
+	w := &inotify{
+		fd:   fd,
+		root: root,
+		skip: skip,
+		ch:   make(chan string, 16),
+		dirs: make(map[int]string),
+		done: make(chan struct{}),
+	}
+	if err := w.addTree(root); err != nil {
+		unix.Close(fd)
+		return nil, err
+	}
+	go w.read()
+	return w, nil
+}
+
+// addTree watches the root directory and the directories below it. Running out of
+// watches is an error, so New can fall back to polling.
+func (w *inotify) addTree(dir string) error {
+	var addErr error
+	err := walk(dir, w.skip, func(path string, d fs.DirEntry) {
+		if !d.IsDir() || addErr != nil {
+			return
+		}
+		wd, err := unix.InotifyAddWatch(w.fd, path, inotifyMask|unix.IN_ONLYDIR)
+		if err != nil {
+			if err == unix.ENOSPC {
+				addErr = err
+			}
+			return
+		}
+		w.mu.Lock()
+		w.dirs[wd] = path
+		w.mu.Unlock()
+	})
+	if addErr != nil {
+		return addErr
+	}
+	return err
+}
+
+// skipped applies skip to a path relative to the root
+func (w *inotify) skipped(rel string, isDir bool) bool {
+	return w.skip != nil && w.skip(rel, isDir)
+}
+
+// read delivers events until close. It owns the descriptor, so the
+// descriptor is never closed under a pending read.
+func (w *inotify) read() {
+	defer close(w.ch)
+	defer unix.Close(w.fd)
+	buf := make([]byte, 64*1024)
+	fds := []unix.PollFd{{Fd: int32(w.fd), Events: unix.POLLIN}}
+	for {
+		select {
+		case <-w.done:
+			return
+		default:
+		}
+
+		// Poll with a timeout, so close() is noticed
+		n, err := unix.Poll(fds, 500)
+		if err != nil && err != unix.EINTR {
+			return
+		}
+		if n <= 0 {
+			continue
+		}
+		n, err = unix.Read(w.fd, buf)
+		if err != nil {
+			if err == unix.EAGAIN || err == unix.EINTR {
+				continue
+			}
+			return
+		}
+		w.handle(buf[:n])
+	}
+}
+
+// handle parses a buffer of inotify events
+func (w *inotify) handle(buf []byte) {
+	for offset := 0; offset+unix.SizeofInotifyEvent <= len(buf); {
+		event := (*unix.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+		nameBytes := buf[offset+unix.SizeofInotifyEvent : offset+unix.SizeofInotifyEvent+int(event.Len)]
+		offset += unix.SizeofInotifyEvent + int(event.Len)
+
+		name := string(nameBytes)
+		for len(name) > 0 && name[len(name)-1] == 0 {
+			name = name[:len(name)-1]
+		}
+
+		w.mu.Lock()
+		dir, ok := w.dirs[int(event.Wd)]
+		if event.Mask&unix.IN_IGNORED != 0 {
+			delete(w.dirs, int(event.Wd))
+		}
+		w.mu.Unlock()
+
+		if event.Mask&unix.IN_Q_OVERFLOW != 0 {
+			w.send(w.root)
+			continue
+		}
+		if !ok || name == "" {
+			continue
+		}
+
+		path := filepath.Join(dir, name)
+		rel, err := filepath.Rel(w.root, path)
+		if err != nil {
+			continue
+		}
+		isDir := event.Mask&unix.IN_ISDIR != 0
+		if w.skipped(filepath.ToSlash(rel), isDir) {
+			continue
+		}
+		if isDir && event.Mask&(unix.IN_CREATE|unix.IN_MOVED_TO) != 0 {
+			w.addSubtree(path)
+		}
+		w.send(path)
+	}
+}
+
+// addSubtree watches a directory that appeared after the watch started
+func (w *inotify) addSubtree(dir string) {
+	skip := func(rel string, isDir bool) bool {
+		full, err := filepath.Rel(w.root, filepath.Join(dir, rel))
+		return err == nil && w.skipped(filepath.ToSlash(full), isDir)
+	}
+	walk(dir, skip, func(path string, d fs.DirEntry) {
+		if !d.IsDir() {
+			return
+		}
+		if wd, err := unix.InotifyAddWatch(w.fd, path, inotifyMask|unix.IN_ONLYDIR); err == nil {
+			w.mu.Lock()
+			w.dirs[wd] = path
+			w.mu.Unlock()
+		}
+	})
+}
+
+func (w *inotify) send(path string) {
+	select {
+	case w.ch <- path:
+	default: // A burst is already being reported
+	}
+}
+
+func (w *inotify) events() <-chan string { return w.ch }
+
+func (w *inotify) close() error {
+	close(w.done)
+	return nil
+}
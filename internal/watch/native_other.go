@@ -0,0 +1,10 @@
+//go:build !linux
+
+package watch
+
+import "errors"
+
+// newNative has no native backend outside Linux; New polls instead
+func newNative(root string, skip SkipFunc) (backend, error) {
+	return nil, errors.New("no native file watching on this platform")
+}
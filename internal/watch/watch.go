@@ -0,0 +1,198 @@
+// Package watch reports changes in a directory tree. It uses inotify where
+// available and falls back to polling.
+package watch
+
+import (
+	"io/fs"
+	"path/filepath"
+	"time"
+)
+
+// SkipFunc reports whether a path (slash-separated, relative to the root)
+// should not be watched. Skipping a directory skips everything inside.
+type SkipFunc func(rel string, isDir bool) bool
+
+// backend delivers the paths of changed files
+type backend interface {
+	events() <-chan string
+	close() error
+}
+
+// Watcher reports debounced changes in a directory tree
+type Watcher struct {
+	backend  backend
+	changes  chan struct{}
+	debounce time.Duration
+	done     chan struct{}
+}
+
+// pollInterval is how often the polling backend rescans the tree
+const pollInterval = time.Second
+
+// New watches the tree at root. A change is reported once no further
+// change has happened for debounce.
+func New(root string, skip SkipFunc, debounce time.Duration) (*Watcher, error) {
+	b, err := newNative(root, skip)
+	if err != nil {
+		b, err = newPoller(root, skip, pollInterval)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	w := &Watcher{
+		backend:  b,
+		changes:  make(chan struct{}, 1),
+		debounce: debounce,
+		done:     make(chan struct{}),
+	}
+	go w.run()
+	return w, nil
+}
+
+// Changes delivers a value after each burst of changes
+func (w *Watcher) Changes() <-chan struct{} {
+	return w.changes
+}
+
+// Close stops watching
+func (w *Watcher) Close() error {
+	close(w.done)
+	return w.backend.close()
+}
+
+// run debounces backend events into changes
+func (w *Watcher) run() {
+	var timer *time.Timer
+	var fire <-chan time.Time
+	events := w.backend.events()
+	for {
+		select {
+		case _, ok := <-events:
+			if !ok {
+				return
+			}
+			if timer == nil {
+				timer = time.NewTimer(w.debounce)
+			} else {
+				timer.Reset(w.debounce)
+			}
+			fire = timer.C
+
+		case <-fire:
+			fire = nil
+			select {
+			case w.changes <- struct{}{}:
+			default: // A change is already pending
+			}
+
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// walk calls fn for every directory and file under root that is not
+// skipped
+func walk(root string, skip SkipFunc, fn func(path string, d fs.DirEntry)) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			return nil // Vanished or unreadable; not worth failing over
+		}
+		if path != root {
+			rel, _ := filepath.Rel(root, path)
+			if skip != nil && skip(filepath.ToSlash(rel), d.IsDir()) {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+		}
+		fn(path, d)
+		return nil
+	})
+}
+
+// poller rescans the tree and compares file modification times and sizes
+type poller struct {
+	ch   chan string
+	done chan struct{}
+}
+
+type fileState struct {
+	mod  time.Time
+	size int64
+}
+
+func newPoller(root string, skip SkipFunc, interval time.Duration) (*poller, error) {
+	snapshot := func() (map[string]fileState, error) {
+		state := make(map[string]fileState)
+		err := walk(root, skip, func(path string, d fs.DirEntry) {
+			// Directories only count as present: their times change with
+			// skipped files too
+			if d.IsDir() {
+				state[path] = fileState{}
+			} else if info, err := d.Info(); err == nil {
+				state[path] = fileState{info.ModTime(), info.Size()}
+			}
+		})
+		return state, err
+	}
+
+	prev, err := snapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	p := &poller{ch: make(chan string, 16), done: make(chan struct{})}
+	go func() {
+		defer close(p.ch)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+			case <-p.done:
+				return
+			}
+			cur, err := snapshot()
+			if err != nil {
+				continue
+			}
+			if changed := diff(prev, cur); changed != "" {
+				select {
+				case p.ch <- changed:
+				case <-p.done:
+					return
+				}
+			}
+			prev = cur
+		}
+	}()
+	return p, nil
+}
+
+// diff returns a path that differs between two snapshots, or ""
+func diff(prev, cur map[string]fileState) string {
+	for path, state := range cur {
+		if old, ok := prev[path]; !ok || old != state {
+			return path
+		}
+	}
+	for path := range prev {
+		if _, ok := cur[path]; !ok {
+			return path
+		}
+	}
+	return ""
+}
+
+func (p *poller) events() <-chan string { return p.ch }
+
+func (p *poller) close() error {
+	close(p.done)
+	return nil
+}
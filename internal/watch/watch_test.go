@@ -0,0 +1,83 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// skipIgnored skips an "ignored" directory and *.tmp files
+func skipIgnored(rel string, isDir bool) bool {
+	return rel == "ignored" || strings.HasSuffix(rel, ".tmp")
+}
+
+func testWatcher(t *testing.T, w *Watcher, root string) {
+	t.Helper()
+	defer w.Close()
+
+	expect := func(what string, want bool) {
+		t.Helper()
+		select {
+		case <-w.Changes():
+			if !want {
+				t.Errorf("%s: unexpected change", what)
+			}
+		case <-time.After(timeout(want)):
+			if want {
+				t.Errorf("%s: no change reported", what)
+			}
+		}
+	}
+
+	os.WriteFile(filepath.Join(root, "ignored", "a.go"), []byte("x"), 0644)
+	os.WriteFile(filepath.Join(root, "b.tmp"), []byte("x"), 0644)
+	expect("ignored paths", false)
+
+	os.WriteFile(filepath.Join(root, "src", "main.go"), []byte("package main"), 0644)
+	expect("file write", true)
+
+	// A new directory is watched too
+	os.Mkdir(filepath.Join(root, "src", "new"), 0755)
+	expect("new directory", true)
+	time.Sleep(100 * time.Millisecond)
+	os.WriteFile(filepath.Join(root, "src", "new", "x.go"), []byte("x"), 0644)
+	expect("file in new directory", true)
+}
+
+// timeout is how long to wait for a change: long when one is expected,
+// short when checking that none comes
+func timeout(want bool) time.Duration {
+	if want {
+		return 3 * time.Second
+	}
+	return 500 * time.Millisecond
+}
+
+func newTree(t *testing.T) string {
+	root := t.TempDir()
+	os.Mkdir(filepath.Join(root, "src"), 0755)
+	os.Mkdir(filepath.Join(root, "ignored"), 0755)
+	return root
+}
+
+func TestWatcher(t *testing.T) {
+	root := newTree(t)
+	w, err := New(root, skipIgnored, 50*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testWatcher(t, w, root)
+}
+
+func TestPoller(t *testing.T) {
+	root := newTree(t)
+	p, err := newPoller(root, skipIgnored, 100*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := &Watcher{backend: p, changes: make(chan struct{}, 1), debounce: 50 * time.Millisecond, done: make(chan struct{})}
+	go w.run()
+	testWatcher(t, w, root)
+}